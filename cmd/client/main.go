@@ -2,11 +2,13 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/cassette"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/config"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/lcu"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/logging"
@@ -32,21 +34,36 @@ func main() {
 	}
 	logger.Info("configuration loaded", "clientID", cfg.ClientID, "masterAddress", cfg.MasterAddress)
 
-	var lockfile *lcu.LockfileData
+	// riot traffic can be recorded to a cassette to replay it in tests
+	var lcuTransport, pdTransport http.RoundTripper
+	if cfg.RecordPath != "" {
+		recorder := cassette.NewRecorder(cfg.RecordPath, logger)
+		lcuTransport = recorder.Transport(lcu.NewTransport())
+		pdTransport = recorder.Transport(valorant.NewTransport())
+		logger.Warnw("recording riot traffic, tokens and puuids are scrubbed but names are not", "path", cfg.RecordPath)
+	}
 
-	for {
-		lockfile, err = lcu.ReadLockfile()
-		if err == nil {
-			break
-		}
-		time.Sleep(5 * time.Second)
+	valClient := valorant.NewClient(cfg.PDURL, pdTransport, logger)
+	journal, err := lcu.OpenJournal(cfg.JournalPath)
+	if err != nil {
+		logger.Errorw("failed to open friend request journal", "error", err)
+		os.Exit(1)
 	}
+	resolver := lcu.NewResolver(nil, valClient, journal, logger)
+
+	// the node reports itself unavailable until the watcher finds a riot client
+	watcher := lcu.NewWatcher(cfg.LockfilePath, lcu.DefaultWatchInterval, resolver, logger)
+	watcher.SetTransport(lcuTransport)
+	watcher.Start()
 
-	logger.Info("riot client detected", "port", lockfile.Port)
+	sweeper := lcu.NewSweeper(resolver, lcu.DefaultSweepInterval, logger)
+	sweeper.Start()
+
+	if !resolver.Available() {
+		_, err := lcu.FindLockfile(cfg.LockfilePath)
+		logger.Warnw("riot client not found yet, waiting for it", "error", err)
+	}
 
-	lcuClient := lcu.NewClient(lockfile, logger)
-	valClient := valorant.NewClient(logger)
-	resolver := lcu.NewResolver(lcuClient, valClient, logger)
 	client := protocol.NewClient(cfg.MasterAddress, cfg.ClientID, version.Version, resolver, logger)
 
 	for {
@@ -65,6 +82,8 @@ func main() {
 		<-sigCh
 		logger.Info("shutting down...")
 		client.Stop()
+		watcher.Stop()
+		sweeper.Stop()
 		os.Exit(0)
 	}()
 
@@ -0,0 +1,74 @@
+// Command fakelcu runs a fake riot client and PD server so a node can be
+// started without riot installed. point the node at the lockfile it writes with
+// LOCKFILE_PATH and at the PD server with PD_URL
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/lcu/lcutest"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/logging"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/valorant/pdtest"
+)
+
+func main() {
+	lockfile := flag.String("lockfile", "./data/fakelcu/lockfile", "where to write the lockfile")
+	players := flag.String("players", "", "json file with the accounts friend requests can find")
+	pdFixtures := flag.String("pd-fixtures", "", "directory with PD fixtures, the built-in ones if empty")
+	flag.Parse()
+
+	logger, err := logging.NewLogger()
+	if err != nil {
+		fmt.Printf("failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+
+	server := lcutest.NewServer()
+	defer server.Close()
+
+	if *players != "" {
+		data, err := os.ReadFile(*players)
+		if err != nil {
+			logger.Errorw("failed to read players", "error", err)
+			os.Exit(1)
+		}
+		var list []lcutest.Player
+		if err := json.Unmarshal(data, &list); err != nil {
+			logger.Errorw("failed to parse players", "error", err)
+			os.Exit(1)
+		}
+		for _, p := range list {
+			server.AddPlayer(p)
+		}
+		logger.Infow("players loaded", "count", len(list))
+	}
+
+	if err := server.WriteLockfile(*lockfile); err != nil {
+		logger.Errorw("failed to write lockfile", "error", err)
+		os.Exit(1)
+	}
+	// the riot client removes its lockfile on exit, so does the fake
+	defer os.Remove(*lockfile)
+
+	fixtures := pdtest.Fixtures()
+	if *pdFixtures != "" {
+		fixtures = os.DirFS(*pdFixtures)
+	}
+	pd := pdtest.NewServer(fixtures)
+	defer pd.Close()
+
+	logger.Infow("fake riot client running", "port", server.Port(), "lockfile", *lockfile, "account", server.Account.GameName+"#"+server.Account.GameTag)
+	logger.Infow("fake PD server running", "pdURL", pd.URL())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	logger.Info("shutting down...")
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/config"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+)
+
+const usage = `usage: master [command]
+
+without a command the master server is started
+
+commands:
+  migrate status   list migrations and whether they are applied
+  migrate up       apply pending migrations
+  db export        write a table as jsonl or csv
+                   [-table accounts] [-format jsonl|csv] [-o file]
+  db import        read a table written by db export, newer rows win
+                   [-table accounts] [-format jsonl|csv] [-touch] [file]
+  db backup        copy the live sqlite database to a file
+                   [file], defaults to a timestamped file in BACKUP_DIR
+`
+
+// runCommand runs a one-off subcommand and returns the process exit code
+func runCommand(args []string) int {
+	cfg := config.LoadMasterConfig()
+
+	switch args[0] {
+	case "migrate":
+		return migrateCommand(cfg, args[1:])
+	case "db":
+		return dbCommand(cfg, args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+func migrateCommand(cfg *config.MasterConfig, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	database, err := db.Open(cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "status":
+		statuses, err := database.MigrationStatus(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read migration status: %v\n", err)
+			return 1
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%03d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+		return 0
+
+	case "up":
+		applied, err := database.Migrate(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return 0
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+func dbCommand(cfg *config.MasterConfig, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	database, err := db.New(cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "export", "import":
+		return transferCommand(ctx, database, args[0], args[1:])
+
+	case "backup":
+		var path string
+		if len(args) > 1 {
+			path = args[1]
+			err = database.Backup(ctx, path)
+		} else {
+			path, err = database.BackupInto(ctx, cfg.BackupDir, 0)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		fmt.Printf("backed up to %s\n", path)
+		return 0
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown db command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+// transferCommand runs db export and db import. data goes through stdout and
+// stdin unless a file is given, counts are reported on stderr
+func transferCommand(ctx context.Context, database *db.Database, command string, args []string) int {
+	flags := flag.NewFlagSet("db "+command, flag.ContinueOnError)
+	table := flags.String("table", "accounts", "table to "+command+", one of "+strings.Join(db.TransferTables(), ", "))
+	format := flags.String("format", "", "jsonl or csv, guessed from the file extension when empty")
+	output := flags.String("o", "", "file to export to instead of stdout")
+	touch := flags.Bool("touch", false, "stamp imported accounts with the import time so the cleanup job keeps them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	file := *output
+	if command == "import" {
+		file = flags.Arg(0)
+	}
+	if *format == "" {
+		*format = db.FormatJSONL
+		if strings.EqualFold(filepath.Ext(file), ".csv") {
+			*format = db.FormatCSV
+		}
+	}
+
+	var count int
+	var err error
+	if command == "export" {
+		if file == "" {
+			count, err = database.Export(ctx, *table, *format, os.Stdout)
+		} else {
+			f, createErr := os.Create(file)
+			if createErr != nil {
+				fmt.Fprintf(os.Stderr, "failed to create %s: %v\n", file, createErr)
+				return 1
+			}
+			count, err = database.Export(ctx, *table, *format, f)
+			// a full disk may only show when the last buffered write lands
+			if closeErr := f.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("failed to write %s: %w", file, closeErr)
+			}
+		}
+	} else {
+		var r io.Reader = os.Stdin
+		if file != "" {
+			f, openErr := os.Open(file)
+			if openErr != nil {
+				fmt.Fprintf(os.Stderr, "failed to open %s: %v\n", file, openErr)
+				return 1
+			}
+			defer f.Close()
+			r = f
+		}
+		count, err = database.Import(ctx, *table, *format, r, db.ImportOptions{Touch: *touch})
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", command, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%sed %d %s rows\n", command, count, *table)
+	return 0
+}
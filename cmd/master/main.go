@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"connectrpc.com/connect"
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/gen/v1connect"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/api"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/cache"
@@ -15,13 +19,19 @@ import (
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/logging"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/protocol"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/scheduler"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/version"
 	"go.uber.org/zap"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	logger, err := logging.NewLogger()
 	if err != nil {
 		fmt.Printf("failed to initialize logger: %v\n", err)
@@ -34,18 +44,29 @@ func main() {
 	cfg := config.LoadMasterConfig()
 	logger.Info("configuration loaded", zap.Int("tcpPort", cfg.TCPPort), zap.Int("apiPort", cfg.APIPort))
 
-	database, err := db.New(cfg.DatabasePath)
+	database, err := db.New(cfg.DatabaseURL)
 	if err != nil {
 		logger.Error("failed to initialize database", zap.Error(err))
 		os.Exit(1)
 	}
 	defer database.Close()
-	logger.Info("database initialized", zap.String("path", cfg.DatabasePath))
+	logger.Info("database initialized", zap.String("engine", database.Dialect()))
 
-	memCache := cache.New(cfg.CacheTTL)
-	logger.Info("cache initialized", zap.Duration("ttl", cfg.CacheTTL))
+	jobs := scheduler.New(jobStore{database}, logger)
+	addMaintenanceJobs(jobs, database, cfg)
+	jobs.Start()
+	defer jobs.Stop()
 
-	tcpServer, err := protocol.NewServer(cfg.TCPPort, logger)
+	accountCache, negativeCache, err := newCaches(cfg)
+	if err != nil {
+		logger.Error("failed to initialize cache", zap.Error(err))
+		os.Exit(1)
+	}
+	defer accountCache.Stop()
+	defer negativeCache.Stop()
+	logger.Info("cache initialized", zap.String("backend", cfg.CacheBackend), zap.Duration("ttl", cfg.StaleFor), zap.Int("maxEntries", cfg.CacheEntries), zap.Int64("maxBytes", cfg.CacheBytes))
+
+	tcpServer, err := protocol.NewServer(cfg.TCPPort, database, logger)
 	if err != nil {
 		logger.Error("failed to start TCP server", zap.Error(err))
 		os.Exit(1)
@@ -58,17 +79,55 @@ func main() {
 		}
 	}()
 
-	apiService := api.NewService(tcpServer, memCache, database, logger)
+	freshness := api.Freshness{Fresh: cfg.FreshFor, Stale: cfg.StaleFor, NotFound: cfg.NotFoundFor}
+	apiService := api.NewService(tcpServer, accountCache, negativeCache, database, freshness, logger)
 
 	mux := http.NewServeMux()
 
-	path, handler := v1connect.NewValorantAPIHandler(apiService)
+	auditInterceptor := api.NewAuditInterceptor(database, logger)
+	path, handler := v1connect.NewValorantAPIHandler(apiService, connect.WithInterceptors(auditInterceptor))
 	mux.Handle(path, handler)
 
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "OK - %d clients connected", tcpServer.GetClientCount())
 	})
 
+	// the admin api exposes node addresses and internals, it gets its own
+	// listener, loopback only unless configured otherwise
+	adminMux := http.NewServeMux()
+
+	adminService := api.NewAdminService(database, tcpServer, logger)
+	adminPath, adminHandler := v1connect.NewAdminAPIHandler(adminService, connect.WithInterceptors(auditInterceptor))
+	adminMux.Handle(adminPath, adminHandler)
+
+	adminMux.HandleFunc("/admin/cache", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]cache.Stats{
+			"accounts":  accountCache.Stats(),
+			"negatives": negativeCache.Stats(),
+		})
+	})
+
+	adminMux.HandleFunc("/admin/nodes", func(w http.ResponseWriter, r *http.Request) {
+		resp, err := adminService.ListNodes(r.Context(), connect.NewRequest(&v1.ListNodesRequest{}))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body, err := protojson.Marshal(resp.Msg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+
+	adminMux.HandleFunc("/admin/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jobs.Status())
+	})
+
 	addr := fmt.Sprintf(":%d", cfg.APIPort)
 	logger.Info("starting API server", zap.Int("port", cfg.APIPort), zap.String("version", version.Version))
 
@@ -77,11 +136,29 @@ func main() {
 		Handler: h2c.NewHandler(mux, &http2.Server{}),
 	}
 
+	adminServer := &http.Server{
+		Addr:    cfg.AdminAddress,
+		Handler: h2c.NewHandler(api.RequireToken(cfg.AdminToken, adminMux), &http2.Server{}),
+	}
+	if cfg.AdminAddress != "" {
+		if cfg.AdminToken == "" && !isLoopback(cfg.AdminAddress) {
+			logger.Warn("admin api listens beyond loopback without ADMIN_TOKEN", zap.String("address", cfg.AdminAddress))
+		}
+		logger.Info("starting admin API server", zap.String("address", cfg.AdminAddress))
+		go func() {
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("admin api server error", zap.Error(err))
+				os.Exit(1)
+			}
+		}()
+	}
+
 	go func() {
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 		<-sigCh
 		logger.Info("shutting down...")
+		adminServer.Shutdown(context.Background())
 		server.Shutdown(context.Background())
 	}()
 
@@ -92,3 +169,66 @@ func main() {
 
 	logger.Info("server stopped")
 }
+
+// accounts stay cached as long as they can be served, stale ones included
+func newCaches(cfg *config.MasterConfig) (cache.Cache[*v1.AccountData], cache.Cache[*api.NegativeLookup], error) {
+	switch cfg.CacheBackend {
+	case "memory":
+		accountCache := cache.NewMemory(cache.MemoryOptions[*v1.AccountData]{
+			TTL:        cfg.StaleFor,
+			MaxEntries: cfg.CacheEntries,
+			MaxBytes:   cfg.CacheBytes,
+			Size:       api.AccountCacheSize,
+		})
+
+		// not found entries are small and short lived, a quarter of the budget is plenty
+		negativeCache := cache.NewMemory(cache.MemoryOptions[*api.NegativeLookup]{
+			TTL:        cfg.NotFoundFor,
+			MaxEntries: cfg.CacheEntries,
+			MaxBytes:   cfg.CacheBytes / 4,
+			Size:       api.NegativeCacheSize,
+		})
+
+		return accountCache, negativeCache, nil
+
+	case "redis":
+		accountCache, err := cache.NewRedis(cache.RedisOptions[*v1.AccountData]{
+			URL:    cfg.RedisURL,
+			Prefix: cfg.RedisPrefix + "account:",
+			TTL:    cfg.StaleFor,
+			Codec:  cache.ProtoCodec(func() *v1.AccountData { return &v1.AccountData{} }),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		negativeCache, err := cache.NewRedis(cache.RedisOptions[*api.NegativeLookup]{
+			URL:    cfg.RedisURL,
+			Prefix: cfg.RedisPrefix + "negative:",
+			TTL:    cfg.NotFoundFor,
+			Codec:  cache.JSONCodec[*api.NegativeLookup](),
+		})
+		if err != nil {
+			accountCache.Stop()
+			return nil, nil, err
+		}
+
+		return accountCache, negativeCache, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown cache backend %q", cfg.CacheBackend)
+	}
+}
+
+// isLoopback reports whether a listen address only accepts local connections
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/api"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/config"
+)
+
+func TestCachesKeepStaleAccounts(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	cfg := config.LoadMasterConfig()
+	cfg.CacheBackend = "redis"
+	cfg.RedisURL = "redis://" + server.Addr()
+	cfg.FreshFor = time.Hour
+	cfg.StaleFor = 24 * time.Hour
+
+	accounts, negatives, err := newCaches(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer accounts.Stop()
+	defer negatives.Stop()
+
+	// an account leaves the cache when it can no longer be served, not
+	// when it turns stale
+	accounts.Set("player#euw", &v1.AccountData{Puuid: "puuid-1"})
+	if ttl := server.TTL(cfg.RedisPrefix + "account:player#euw"); ttl != cfg.StaleFor {
+		t.Errorf("account cached for %v, want %v", ttl, cfg.StaleFor)
+	}
+
+	negatives.Set("player#euw", &api.NegativeLookup{Code: "NOT_FOUND"})
+	if ttl := server.TTL(cfg.RedisPrefix + "negative:player#euw"); ttl != cfg.NotFoundFor {
+		t.Errorf("not found cached for %v, want %v", ttl, cfg.NotFoundFor)
+	}
+}
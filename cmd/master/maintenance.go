@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/config"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/scheduler"
+)
+
+// now is replaced by tests to run the jobs at a later time
+var now = time.Now
+
+// addMaintenanceJobs registers the jobs keeping the database from growing forever
+func addMaintenanceJobs(s *scheduler.Scheduler, database *db.Database, cfg *config.MasterConfig) {
+	s.Add(scheduler.Job{
+		Name:     "clean_old_accounts",
+		Interval: cfg.CleanupInterval,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			return database.CleanOldAccounts(ctx, now().UTC().Add(-cfg.AccountRetention))
+		},
+	})
+
+	s.Add(scheduler.Job{
+		Name:     "clean_old_logs",
+		Interval: cfg.CleanupInterval,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			return database.CleanOldLogs(ctx, now().UTC().Add(-cfg.LogRetention))
+		},
+	})
+
+	s.Add(scheduler.Job{
+		Name:     "clean_stale_clients",
+		Interval: cfg.CleanupInterval,
+		Timeout:  time.Minute,
+		Run: func(ctx context.Context) error {
+			return database.CleanStaleClients(ctx, now().UTC().Add(-cfg.ClientRetention))
+		},
+	})
+
+	s.Add(scheduler.Job{
+		Name:     "clean_expired_negative_lookups",
+		Interval: cfg.CleanupInterval,
+		Timeout:  time.Minute,
+		Run: func(ctx context.Context) error {
+			return database.CleanExpiredNegativeLookups(ctx, now().UTC())
+		},
+	})
+
+	s.Add(scheduler.Job{
+		Name:     "optimize",
+		Interval: cfg.OptimizeInterval,
+		Timeout:  10 * time.Minute,
+		Run:      database.Optimize,
+	})
+
+	s.Add(scheduler.Job{
+		Name:     "vacuum",
+		Interval: cfg.VacuumInterval,
+		Run:      database.Vacuum,
+	})
+
+	// postgres is backed up with its own tooling
+	if database.Dialect() == db.SQLite {
+		s.Add(scheduler.Job{
+			Name:     "backup",
+			Interval: cfg.BackupInterval,
+			Run: func(ctx context.Context) error {
+				_, err := database.BackupInto(ctx, cfg.BackupDir, cfg.BackupKeep)
+				return err
+			},
+		})
+	}
+}
+
+// jobStore keeps the schedule in the database so restarts don't delay the jobs
+type jobStore struct {
+	database *db.Database
+}
+
+func (s jobStore) LastRuns(ctx context.Context) (map[string]time.Time, error) {
+	runs, err := s.database.GetJobRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lastRuns := make(map[string]time.Time, len(runs))
+	for _, run := range runs {
+		lastRuns[run.Name] = run.LastRun
+	}
+	return lastRuns, nil
+}
+
+func (s jobStore) RecordRun(ctx context.Context, job string, started time.Time) error {
+	return s.database.RecordJobRun(ctx, db.RecordJobRunParams{Name: job, LastRun: started.UTC()})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"connectrpc.com/connect"
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/api"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/config"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/protocol"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/scheduler"
+)
+
+func TestCleanupKeepsDisconnectedNodes(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	ctx := context.Background()
+
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	if err := database.RegisterClient(ctx, db.RegisterClientParams{ClientID: "node-1", Version: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.RecordClientRequest(ctx, db.RecordClientRequestParams{ClientID: "node-1", LatencyMs: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the node disconnected and the cleanup runs ten minutes later
+	now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	defer func() { now = time.Now }()
+
+	cfg := config.LoadMasterConfig()
+	cfg.OptimizeInterval = 0
+	cfg.VacuumInterval = 0
+	cfg.BackupInterval = 0
+
+	jobs := scheduler.New(nil, logger)
+	addMaintenanceJobs(jobs, database, cfg)
+	jobs.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cleanedUp(jobs) {
+		if time.Now().After(deadline) {
+			t.Fatal("cleanup jobs didn't run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	jobs.Stop()
+	for _, status := range jobs.Status() {
+		if status.Failures > 0 {
+			t.Errorf("job %s failed: %s", status.Name, status.LastError)
+		}
+	}
+
+	tcpServer, err := protocol.NewServer(0, database, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpServer.Stop()
+
+	resp, err := api.NewAdminService(database, tcpServer, logger).ListNodes(ctx, connect.NewRequest(&v1.ListNodesRequest{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Msg.Data) != 1 {
+		t.Fatalf("got %d nodes, want the disconnected node kept", len(resp.Msg.Data))
+	}
+	node := resp.Msg.Data[0]
+	if node.ClientId != "node-1" || node.RequestsServed != 1 {
+		t.Errorf("lifetime counters lost: %+v", node)
+	}
+}
+
+// cleanedUp reports whether every job has run once
+func cleanedUp(jobs *scheduler.Scheduler) bool {
+	for _, status := range jobs.Status() {
+		if status.Runs == 0 {
+			return false
+		}
+	}
+	return true
+}
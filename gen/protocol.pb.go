@@ -20,6 +20,64 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type NodeStatus int32
+
+const (
+	NodeStatus_NODE_STATUS_UNSPECIFIED  NodeStatus = 0
+	NodeStatus_NODE_STATUS_UNAVAILABLE  NodeStatus = 1
+	NodeStatus_NODE_STATUS_LOGGED_OUT   NodeStatus = 2
+	NodeStatus_NODE_STATUS_PATCHING     NodeStatus = 3
+	NodeStatus_NODE_STATUS_RATE_LIMITED NodeStatus = 4
+	NodeStatus_NODE_STATUS_READY        NodeStatus = 5
+)
+
+// Enum value maps for NodeStatus.
+var (
+	NodeStatus_name = map[int32]string{
+		0: "NODE_STATUS_UNSPECIFIED",
+		1: "NODE_STATUS_UNAVAILABLE",
+		2: "NODE_STATUS_LOGGED_OUT",
+		3: "NODE_STATUS_PATCHING",
+		4: "NODE_STATUS_RATE_LIMITED",
+		5: "NODE_STATUS_READY",
+	}
+	NodeStatus_value = map[string]int32{
+		"NODE_STATUS_UNSPECIFIED":  0,
+		"NODE_STATUS_UNAVAILABLE":  1,
+		"NODE_STATUS_LOGGED_OUT":   2,
+		"NODE_STATUS_PATCHING":     3,
+		"NODE_STATUS_RATE_LIMITED": 4,
+		"NODE_STATUS_READY":        5,
+	}
+)
+
+func (x NodeStatus) Enum() *NodeStatus {
+	p := new(NodeStatus)
+	*p = x
+	return p
+}
+
+func (x NodeStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NodeStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_protocol_proto_enumTypes[0].Descriptor()
+}
+
+func (NodeStatus) Type() protoreflect.EnumType {
+	return &file_protocol_proto_enumTypes[0]
+}
+
+func (x NodeStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NodeStatus.Descriptor instead.
+func (NodeStatus) EnumDescriptor() ([]byte, []int) {
+	return file_protocol_proto_rawDescGZIP(), []int{0}
+}
+
 type Message struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -207,8 +265,12 @@ type ClientHeartbeat struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Timestamp    int64 `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	LcuAvailable bool  `protobuf:"varint,2,opt,name=lcu_available,json=lcuAvailable,proto3" json:"lcu_available,omitempty"`
+	Timestamp            int64      `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	LcuAvailable         bool       `protobuf:"varint,2,opt,name=lcu_available,json=lcuAvailable,proto3" json:"lcu_available,omitempty"`
+	GameVersion          string     `protobuf:"bytes,3,opt,name=game_version,json=gameVersion,proto3" json:"game_version,omitempty"`
+	Status               NodeStatus `protobuf:"varint,4,opt,name=status,proto3,enum=internal.v1.NodeStatus" json:"status,omitempty"`
+	StatusDetail         string     `protobuf:"bytes,5,opt,name=status_detail,json=statusDetail,proto3" json:"status_detail,omitempty"`
+	FriendRequestBacklog int64      `protobuf:"varint,6,opt,name=friend_request_backlog,json=friendRequestBacklog,proto3" json:"friend_request_backlog,omitempty"`
 }
 
 func (x *ClientHeartbeat) Reset() {
@@ -255,6 +317,34 @@ func (x *ClientHeartbeat) GetLcuAvailable() bool {
 	return false
 }
 
+func (x *ClientHeartbeat) GetGameVersion() string {
+	if x != nil {
+		return x.GameVersion
+	}
+	return ""
+}
+
+func (x *ClientHeartbeat) GetStatus() NodeStatus {
+	if x != nil {
+		return x.Status
+	}
+	return NodeStatus_NODE_STATUS_UNSPECIFIED
+}
+
+func (x *ClientHeartbeat) GetStatusDetail() string {
+	if x != nil {
+		return x.StatusDetail
+	}
+	return ""
+}
+
+func (x *ClientHeartbeat) GetFriendRequestBacklog() int64 {
+	if x != nil {
+		return x.FriendRequestBacklog
+	}
+	return 0
+}
+
 type ResolveAccountRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -475,12 +565,23 @@ var file_protocol_proto_rawDesc = []byte{
 	0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
 	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x22, 0x54, 0x0a, 0x0f, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x48, 0x65,
-	0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
-	0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
-	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x63, 0x75, 0x5f, 0x61, 0x76, 0x61,
-	0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x6c, 0x63,
-	0x75, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x4f, 0x0a, 0x15, 0x52, 0x65,
+	0x73, 0x69, 0x6f, 0x6e, 0x22, 0x83, 0x02, 0x0a, 0x0f, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x48,
+	0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x63, 0x75, 0x5f, 0x61, 0x76,
+	0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x6c,
+	0x63, 0x75, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x67,
+	0x61, 0x6d, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x67, 0x61, 0x6d, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2f,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17,
+	0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64,
+	0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x23, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x44, 0x65,
+	0x74, 0x61, 0x69, 0x6c, 0x12, 0x34, 0x0a, 0x16, 0x66, 0x72, 0x69, 0x65, 0x6e, 0x64, 0x5f, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x6c, 0x6f, 0x67, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x14, 0x66, 0x72, 0x69, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x42, 0x61, 0x63, 0x6b, 0x6c, 0x6f, 0x67, 0x22, 0x4f, 0x0a, 0x15, 0x52, 0x65,
 	0x73, 0x6f, 0x6c, 0x76, 0x65, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x67, 0x61, 0x6d, 0x65, 0x4e, 0x61, 0x6d, 0x65,
@@ -499,9 +600,20 @@ var file_protocol_proto_rawDesc = []byte{
 	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
 	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x42, 0x17, 0x5a, 0x15, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
-	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x67, 0x65, 0x2a, 0xb1, 0x01, 0x0a, 0x0a, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x1b, 0x0a, 0x17, 0x4e, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1b,
+	0x0a, 0x17, 0x4e, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e,
+	0x41, 0x56, 0x41, 0x49, 0x4c, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x4e,
+	0x4f, 0x44, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x4c, 0x4f, 0x47, 0x47, 0x45,
+	0x44, 0x5f, 0x4f, 0x55, 0x54, 0x10, 0x02, 0x12, 0x18, 0x0a, 0x14, 0x4e, 0x4f, 0x44, 0x45, 0x5f,
+	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x50, 0x41, 0x54, 0x43, 0x48, 0x49, 0x4e, 0x47, 0x10,
+	0x03, 0x12, 0x1c, 0x0a, 0x18, 0x4e, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x5f, 0x52, 0x41, 0x54, 0x45, 0x5f, 0x4c, 0x49, 0x4d, 0x49, 0x54, 0x45, 0x44, 0x10, 0x04, 0x12,
+	0x15, 0x0a, 0x11, 0x4e, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52,
+	0x45, 0x41, 0x44, 0x59, 0x10, 0x05, 0x42, 0x17, 0x5a, 0x15, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x76, 0x31, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -516,26 +628,29 @@ func file_protocol_proto_rawDescGZIP() []byte {
 	return file_protocol_proto_rawDescData
 }
 
+var file_protocol_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_protocol_proto_goTypes = []any{
-	(*Message)(nil),                // 0: internal.v1.Message
-	(*ClientRegister)(nil),         // 1: internal.v1.ClientRegister
-	(*ClientHeartbeat)(nil),        // 2: internal.v1.ClientHeartbeat
-	(*ResolveAccountRequest)(nil),  // 3: internal.v1.ResolveAccountRequest
-	(*ResolveAccountResponse)(nil), // 4: internal.v1.ResolveAccountResponse
-	(*ErrorResponse)(nil),          // 5: internal.v1.ErrorResponse
+	(NodeStatus)(0),                // 0: internal.v1.NodeStatus
+	(*Message)(nil),                // 1: internal.v1.Message
+	(*ClientRegister)(nil),         // 2: internal.v1.ClientRegister
+	(*ClientHeartbeat)(nil),        // 3: internal.v1.ClientHeartbeat
+	(*ResolveAccountRequest)(nil),  // 4: internal.v1.ResolveAccountRequest
+	(*ResolveAccountResponse)(nil), // 5: internal.v1.ResolveAccountResponse
+	(*ErrorResponse)(nil),          // 6: internal.v1.ErrorResponse
 }
 var file_protocol_proto_depIdxs = []int32{
-	1, // 0: internal.v1.Message.client_register:type_name -> internal.v1.ClientRegister
-	2, // 1: internal.v1.Message.client_heartbeat:type_name -> internal.v1.ClientHeartbeat
-	3, // 2: internal.v1.Message.resolve_account_request:type_name -> internal.v1.ResolveAccountRequest
-	4, // 3: internal.v1.Message.resolve_account_response:type_name -> internal.v1.ResolveAccountResponse
-	5, // 4: internal.v1.Message.error_response:type_name -> internal.v1.ErrorResponse
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	2, // 0: internal.v1.Message.client_register:type_name -> internal.v1.ClientRegister
+	3, // 1: internal.v1.Message.client_heartbeat:type_name -> internal.v1.ClientHeartbeat
+	4, // 2: internal.v1.Message.resolve_account_request:type_name -> internal.v1.ResolveAccountRequest
+	5, // 3: internal.v1.Message.resolve_account_response:type_name -> internal.v1.ResolveAccountResponse
+	6, // 4: internal.v1.Message.error_response:type_name -> internal.v1.ErrorResponse
+	0, // 5: internal.v1.ClientHeartbeat.status:type_name -> internal.v1.NodeStatus
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_protocol_proto_init() }
@@ -555,13 +670,14 @@ func file_protocol_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_protocol_proto_rawDesc,
-			NumEnums:      0,
+			NumEnums:      1,
 			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_protocol_proto_goTypes,
 		DependencyIndexes: file_protocol_proto_depIdxs,
+		EnumInfos:         file_protocol_proto_enumTypes,
 		MessageInfos:      file_protocol_proto_msgTypes,
 	}.Build()
 	File_protocol_proto = out.File
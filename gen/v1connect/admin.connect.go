@@ -0,0 +1,137 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: admin.proto
+
+package v1connect
+
+import (
+	context "context"
+	errors "errors"
+	http "net/http"
+	strings "strings"
+
+	connect "connectrpc.com/connect"
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// AdminAPIName is the fully-qualified name of the AdminAPI service.
+	AdminAPIName = "api.v1.AdminAPI"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// AdminAPIGetStatsProcedure is the fully-qualified name of the AdminAPI's GetStats RPC.
+	AdminAPIGetStatsProcedure = "/api.v1.AdminAPI/GetStats"
+	// AdminAPIListNodesProcedure is the fully-qualified name of the AdminAPI's ListNodes RPC.
+	AdminAPIListNodesProcedure = "/api.v1.AdminAPI/ListNodes"
+)
+
+// AdminAPIClient is a client for the api.v1.AdminAPI service.
+type AdminAPIClient interface {
+	GetStats(context.Context, *connect.Request[v1.GetStatsRequest]) (*connect.Response[v1.GetStatsResponse], error)
+	ListNodes(context.Context, *connect.Request[v1.ListNodesRequest]) (*connect.Response[v1.ListNodesResponse], error)
+}
+
+// NewAdminAPIClient constructs a client for the api.v1.AdminAPI service. By default, it uses the
+// Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAdminAPIClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) AdminAPIClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	adminAPIMethods := v1.File_admin_proto.Services().ByName("AdminAPI").Methods()
+	return &adminAPIClient{
+		getStats: connect.NewClient[v1.GetStatsRequest, v1.GetStatsResponse](
+			httpClient,
+			baseURL+AdminAPIGetStatsProcedure,
+			connect.WithSchema(adminAPIMethods.ByName("GetStats")),
+			connect.WithClientOptions(opts...),
+		),
+		listNodes: connect.NewClient[v1.ListNodesRequest, v1.ListNodesResponse](
+			httpClient,
+			baseURL+AdminAPIListNodesProcedure,
+			connect.WithSchema(adminAPIMethods.ByName("ListNodes")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// adminAPIClient implements AdminAPIClient.
+type adminAPIClient struct {
+	getStats  *connect.Client[v1.GetStatsRequest, v1.GetStatsResponse]
+	listNodes *connect.Client[v1.ListNodesRequest, v1.ListNodesResponse]
+}
+
+// GetStats calls api.v1.AdminAPI.GetStats.
+func (c *adminAPIClient) GetStats(ctx context.Context, req *connect.Request[v1.GetStatsRequest]) (*connect.Response[v1.GetStatsResponse], error) {
+	return c.getStats.CallUnary(ctx, req)
+}
+
+// ListNodes calls api.v1.AdminAPI.ListNodes.
+func (c *adminAPIClient) ListNodes(ctx context.Context, req *connect.Request[v1.ListNodesRequest]) (*connect.Response[v1.ListNodesResponse], error) {
+	return c.listNodes.CallUnary(ctx, req)
+}
+
+// AdminAPIHandler is an implementation of the api.v1.AdminAPI service.
+type AdminAPIHandler interface {
+	GetStats(context.Context, *connect.Request[v1.GetStatsRequest]) (*connect.Response[v1.GetStatsResponse], error)
+	ListNodes(context.Context, *connect.Request[v1.ListNodesRequest]) (*connect.Response[v1.ListNodesResponse], error)
+}
+
+// NewAdminAPIHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAdminAPIHandler(svc AdminAPIHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	adminAPIMethods := v1.File_admin_proto.Services().ByName("AdminAPI").Methods()
+	adminAPIGetStatsHandler := connect.NewUnaryHandler(
+		AdminAPIGetStatsProcedure,
+		svc.GetStats,
+		connect.WithSchema(adminAPIMethods.ByName("GetStats")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminAPIListNodesHandler := connect.NewUnaryHandler(
+		AdminAPIListNodesProcedure,
+		svc.ListNodes,
+		connect.WithSchema(adminAPIMethods.ByName("ListNodes")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/api.v1.AdminAPI/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AdminAPIGetStatsProcedure:
+			adminAPIGetStatsHandler.ServeHTTP(w, r)
+		case AdminAPIListNodesProcedure:
+			adminAPIListNodesHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAdminAPIHandler returns CodeUnimplemented from all methods.
+type UnimplementedAdminAPIHandler struct{}
+
+func (UnimplementedAdminAPIHandler) GetStats(context.Context, *connect.Request[v1.GetStatsRequest]) (*connect.Response[v1.GetStatsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.v1.AdminAPI.GetStats is not implemented"))
+}
+
+func (UnimplementedAdminAPIHandler) ListNodes(context.Context, *connect.Request[v1.ListNodesRequest]) (*connect.Response[v1.ListNodesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.v1.AdminAPI.ListNodes is not implemented"))
+}
@@ -36,11 +36,19 @@ const (
 const (
 	// ValorantAPIGetAccountProcedure is the fully-qualified name of the ValorantAPI's GetAccount RPC.
 	ValorantAPIGetAccountProcedure = "/api.v1.ValorantAPI/GetAccount"
+	// ValorantAPIGetNameHistoryProcedure is the fully-qualified name of the ValorantAPI's
+	// GetNameHistory RPC.
+	ValorantAPIGetNameHistoryProcedure = "/api.v1.ValorantAPI/GetNameHistory"
+	// ValorantAPIGetAccountHistoryProcedure is the fully-qualified name of the ValorantAPI's
+	// GetAccountHistory RPC.
+	ValorantAPIGetAccountHistoryProcedure = "/api.v1.ValorantAPI/GetAccountHistory"
 )
 
 // ValorantAPIClient is a client for the api.v1.ValorantAPI service.
 type ValorantAPIClient interface {
 	GetAccount(context.Context, *connect.Request[v1.GetAccountRequest]) (*connect.Response[v1.GetAccountResponse], error)
+	GetNameHistory(context.Context, *connect.Request[v1.GetNameHistoryRequest]) (*connect.Response[v1.GetNameHistoryResponse], error)
+	GetAccountHistory(context.Context, *connect.Request[v1.GetAccountHistoryRequest]) (*connect.Response[v1.GetAccountHistoryResponse], error)
 }
 
 // NewValorantAPIClient constructs a client for the api.v1.ValorantAPI service. By default, it uses
@@ -60,12 +68,26 @@ func NewValorantAPIClient(httpClient connect.HTTPClient, baseURL string, opts ..
 			connect.WithSchema(valorantAPIMethods.ByName("GetAccount")),
 			connect.WithClientOptions(opts...),
 		),
+		getNameHistory: connect.NewClient[v1.GetNameHistoryRequest, v1.GetNameHistoryResponse](
+			httpClient,
+			baseURL+ValorantAPIGetNameHistoryProcedure,
+			connect.WithSchema(valorantAPIMethods.ByName("GetNameHistory")),
+			connect.WithClientOptions(opts...),
+		),
+		getAccountHistory: connect.NewClient[v1.GetAccountHistoryRequest, v1.GetAccountHistoryResponse](
+			httpClient,
+			baseURL+ValorantAPIGetAccountHistoryProcedure,
+			connect.WithSchema(valorantAPIMethods.ByName("GetAccountHistory")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // valorantAPIClient implements ValorantAPIClient.
 type valorantAPIClient struct {
-	getAccount *connect.Client[v1.GetAccountRequest, v1.GetAccountResponse]
+	getAccount        *connect.Client[v1.GetAccountRequest, v1.GetAccountResponse]
+	getNameHistory    *connect.Client[v1.GetNameHistoryRequest, v1.GetNameHistoryResponse]
+	getAccountHistory *connect.Client[v1.GetAccountHistoryRequest, v1.GetAccountHistoryResponse]
 }
 
 // GetAccount calls api.v1.ValorantAPI.GetAccount.
@@ -73,9 +95,21 @@ func (c *valorantAPIClient) GetAccount(ctx context.Context, req *connect.Request
 	return c.getAccount.CallUnary(ctx, req)
 }
 
+// GetNameHistory calls api.v1.ValorantAPI.GetNameHistory.
+func (c *valorantAPIClient) GetNameHistory(ctx context.Context, req *connect.Request[v1.GetNameHistoryRequest]) (*connect.Response[v1.GetNameHistoryResponse], error) {
+	return c.getNameHistory.CallUnary(ctx, req)
+}
+
+// GetAccountHistory calls api.v1.ValorantAPI.GetAccountHistory.
+func (c *valorantAPIClient) GetAccountHistory(ctx context.Context, req *connect.Request[v1.GetAccountHistoryRequest]) (*connect.Response[v1.GetAccountHistoryResponse], error) {
+	return c.getAccountHistory.CallUnary(ctx, req)
+}
+
 // ValorantAPIHandler is an implementation of the api.v1.ValorantAPI service.
 type ValorantAPIHandler interface {
 	GetAccount(context.Context, *connect.Request[v1.GetAccountRequest]) (*connect.Response[v1.GetAccountResponse], error)
+	GetNameHistory(context.Context, *connect.Request[v1.GetNameHistoryRequest]) (*connect.Response[v1.GetNameHistoryResponse], error)
+	GetAccountHistory(context.Context, *connect.Request[v1.GetAccountHistoryRequest]) (*connect.Response[v1.GetAccountHistoryResponse], error)
 }
 
 // NewValorantAPIHandler builds an HTTP handler from the service implementation. It returns the path
@@ -91,10 +125,26 @@ func NewValorantAPIHandler(svc ValorantAPIHandler, opts ...connect.HandlerOption
 		connect.WithSchema(valorantAPIMethods.ByName("GetAccount")),
 		connect.WithHandlerOptions(opts...),
 	)
+	valorantAPIGetNameHistoryHandler := connect.NewUnaryHandler(
+		ValorantAPIGetNameHistoryProcedure,
+		svc.GetNameHistory,
+		connect.WithSchema(valorantAPIMethods.ByName("GetNameHistory")),
+		connect.WithHandlerOptions(opts...),
+	)
+	valorantAPIGetAccountHistoryHandler := connect.NewUnaryHandler(
+		ValorantAPIGetAccountHistoryProcedure,
+		svc.GetAccountHistory,
+		connect.WithSchema(valorantAPIMethods.ByName("GetAccountHistory")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/api.v1.ValorantAPI/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case ValorantAPIGetAccountProcedure:
 			valorantAPIGetAccountHandler.ServeHTTP(w, r)
+		case ValorantAPIGetNameHistoryProcedure:
+			valorantAPIGetNameHistoryHandler.ServeHTTP(w, r)
+		case ValorantAPIGetAccountHistoryProcedure:
+			valorantAPIGetAccountHistoryHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -107,3 +157,11 @@ type UnimplementedValorantAPIHandler struct{}
 func (UnimplementedValorantAPIHandler) GetAccount(context.Context, *connect.Request[v1.GetAccountRequest]) (*connect.Response[v1.GetAccountResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.v1.ValorantAPI.GetAccount is not implemented"))
 }
+
+func (UnimplementedValorantAPIHandler) GetNameHistory(context.Context, *connect.Request[v1.GetNameHistoryRequest]) (*connect.Response[v1.GetNameHistoryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.v1.ValorantAPI.GetNameHistory is not implemented"))
+}
+
+func (UnimplementedValorantAPIHandler) GetAccountHistory(context.Context, *connect.Request[v1.GetAccountHistoryRequest]) (*connect.Response[v1.GetAccountHistoryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("api.v1.ValorantAPI.GetAccountHistory is not implemented"))
+}
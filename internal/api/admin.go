@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"connectrpc.com/connect"
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/protocol"
+)
+
+const (
+	defaultStatsWindow = 1 * time.Hour
+	maxStatsWindow     = 90 * 24 * time.Hour
+)
+
+// RequireToken rejects requests that don't carry token as a bearer token, an
+// empty token lets every request through
+func RequireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type AdminService struct {
+	db        *db.Database
+	tcpServer *protocol.Server
+	logger    *zap.SugaredLogger
+}
+
+func NewAdminService(database *db.Database, tcpServer *protocol.Server, logger *zap.SugaredLogger) *AdminService {
+	return &AdminService{
+		db:        database,
+		tcpServer: tcpServer,
+		logger:    logger,
+	}
+}
+
+func (s *AdminService) GetStats(
+	ctx context.Context,
+	req *connect.Request[v1.GetStatsRequest],
+) (*connect.Response[v1.GetStatsResponse], error) {
+	window := time.Duration(req.Msg.WindowSeconds) * time.Second
+	if window <= 0 {
+		window = defaultStatsWindow
+	}
+	if window > maxStatsWindow {
+		window = maxStatsWindow
+	}
+	since := time.Now().UTC().Add(-window)
+
+	stats, err := s.requestStats(ctx, since)
+	if err != nil {
+		s.logger.Errorw("failed to get request stats", "error", err)
+		return connect.NewResponse(&v1.GetStatsResponse{
+			Status: 500,
+			Error:  "failed to get request stats",
+		}), nil
+	}
+	stats.WindowSeconds = int64(window / time.Second)
+
+	return connect.NewResponse(&v1.GetStatsResponse{
+		Status: 200,
+		Data:   stats,
+	}), nil
+}
+
+// ListNodes returns every node the master knows of, connected or not, with
+// its lifetime request counters
+func (s *AdminService) ListNodes(
+	ctx context.Context,
+	req *connect.Request[v1.ListNodesRequest],
+) (*connect.Response[v1.ListNodesResponse], error) {
+	clients, err := s.db.GetAllClients(ctx)
+	if err != nil {
+		s.logger.Errorw("failed to get clients", "error", err)
+		return connect.NewResponse(&v1.ListNodesResponse{
+			Status: 500,
+			Error:  "failed to list nodes",
+		}), nil
+	}
+
+	nodes := make([]*v1.NodeInfo, 0, len(clients))
+	for _, client := range clients {
+		node := &v1.NodeInfo{
+			ClientId:       client.ClientID,
+			Connected:      s.isConnected(client),
+			LcuAvailable:   client.LcuAvailable,
+			Version:        client.Version,
+			GameVersion:    client.GameVersion,
+			RemoteAddress:  client.RemoteAddress,
+			ConnectedAt:    client.ConnectedAt.Format(time.RFC3339),
+			LastHeartbeat:  client.LastHeartbeat.Format(time.RFC3339),
+			RequestsServed: client.RequestsServed,
+			Failures:       client.Failures,
+			LastError:      deref(client.LastError),
+			Status:         client.Status,
+			StatusDetail:   client.StatusDetail,
+
+			FriendRequestBacklog: client.FriendRequestBacklog,
+		}
+		if client.RequestsServed > 0 {
+			node.AvgLatencyMs = float64(client.TotalLatencyMs) / float64(client.RequestsServed)
+		}
+		// a node that dropped its connection can't serve anything, whatever
+		// it last reported
+		if !node.Connected {
+			node.LcuAvailable = false
+			node.Status = protocol.StatusName(v1.NodeStatus_NODE_STATUS_UNAVAILABLE)
+			node.StatusDetail = "not connected"
+		}
+		nodes = append(nodes, node)
+	}
+
+	return connect.NewResponse(&v1.ListNodesResponse{
+		Status: 200,
+		Data:   nodes,
+	}), nil
+}
+
+// isConnected reports whether a node is attached to this master or, when
+// masters share the database, recently heartbeated to another one
+func (s *AdminService) isConnected(client db.Client) bool {
+	if s.tcpServer.IsConnected(client.ClientID) {
+		return true
+	}
+	return time.Since(client.LastHeartbeat) < protocol.HeartbeatTimeout
+}
+
+func (s *AdminService) requestStats(ctx context.Context, since time.Time) (*v1.StatsData, error) {
+	totals, err := s.db.GetRequestStats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &v1.StatsData{
+		TotalRequests:      totals.TotalRequests,
+		SuccessfulRequests: int64(deref(totals.SuccessfulRequests)),
+		SuccessRate:        successRate(totals.TotalRequests, deref(totals.SuccessfulRequests)),
+		AvgDurationMs:      deref(totals.AvgDurationMs),
+	}
+
+	if stats.P50DurationMs, err = s.durationPercentile(ctx, since, totals.TotalRequests, 50); err != nil {
+		return nil, err
+	}
+	if stats.P95DurationMs, err = s.durationPercentile(ctx, since, totals.TotalRequests, 95); err != nil {
+		return nil, err
+	}
+
+	byClient, err := s.db.GetRequestStatsByClient(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range byClient {
+		stats.Nodes = append(stats.Nodes, breakdown(deref(row.ClientID), row.TotalRequests, row.SuccessfulRequests, row.AvgDurationMs))
+	}
+
+	bySource, err := s.db.GetRequestStatsBySource(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range bySource {
+		stats.Sources = append(stats.Sources, breakdown(deref(row.Source), row.TotalRequests, row.SuccessfulRequests, row.AvgDurationMs))
+	}
+
+	return stats, nil
+}
+
+// durationPercentile picks the nearest-rank percentile straight from the
+// database so the window never has to fit in memory
+func (s *AdminService) durationPercentile(ctx context.Context, since time.Time, total int64, percentile int64) (int64, error) {
+	if total == 0 {
+		return 0, nil
+	}
+
+	duration, err := s.db.GetRequestDurationAt(ctx, db.GetRequestDurationAtParams{
+		CreatedAt: since,
+		Offset:    (total - 1) * percentile / 100,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return deref(duration), nil
+}
+
+func breakdown(key string, total int64, successful, avgDuration *float64) *v1.RequestBreakdown {
+	return &v1.RequestBreakdown{
+		Key:                key,
+		TotalRequests:      total,
+		SuccessfulRequests: int64(deref(successful)),
+		SuccessRate:        successRate(total, deref(successful)),
+		AvgDurationMs:      deref(avgDuration),
+	}
+}
+
+func successRate(total int64, successful float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return successful / float64(total)
+}
+
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
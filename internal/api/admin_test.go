@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"connectrpc.com/connect"
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/gen/v1connect"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/protocol"
+)
+
+func TestRequireToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{"no token configured", "", "", http.StatusOK},
+		{"missing", "secret", "", http.StatusUnauthorized},
+		{"wrong", "secret", "Bearer guess", http.StatusUnauthorized},
+		{"not bearer", "secret", "Basic secret", http.StatusUnauthorized},
+		{"valid", "secret", "Bearer secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			RequireToken(tt.token, ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminCallsAudited(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	ctx := context.Background()
+
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	tcpServer, err := protocol.NewServer(0, database, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpServer.Stop()
+
+	mux := http.NewServeMux()
+	mux.Handle(v1connect.NewAdminAPIHandler(NewAdminService(database, tcpServer, logger), connect.WithInterceptors(NewAuditInterceptor(database, logger))))
+	server := httptest.NewServer(RequireToken("secret", mux))
+	defer server.Close()
+
+	client := v1connect.NewAdminAPIClient(server.Client(), server.URL)
+	if _, err := client.ListNodes(ctx, connect.NewRequest(&v1.ListNodesRequest{})); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("call without token returned %v, want %v", err, connect.CodeUnauthenticated)
+	}
+
+	req := connect.NewRequest(&v1.ListNodesRequest{})
+	req.Header().Set("Authorization", "Bearer secret")
+	resp, err := client.ListNodes(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header().Get(RequestIDHeader) == "" {
+		t.Error("admin call not audited, no request id")
+	}
+
+	// audited, but not counted as api traffic
+	statsReq := connect.NewRequest(&v1.GetStatsRequest{})
+	statsReq.Header().Set("Authorization", "Bearer secret")
+	stats, err := client.GetStats(ctx, statsReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Msg.Status != 200 || stats.Msg.Data.TotalRequests != 0 {
+		t.Errorf("stats counted admin calls: %+v", stats.Msg)
+	}
+}
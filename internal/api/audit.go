@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"connectrpc.com/connect"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+)
+
+const RequestIDHeader = "X-Request-Id"
+
+// requestInfo collects what a handler learned about a call so the audit
+// interceptor can record it
+type requestInfo struct {
+	id        string
+	name      string
+	tag       string
+	puuid     string
+	clientID  string
+	source    string
+	errorCode string
+}
+
+type requestInfoKey struct{}
+
+// requestInfoFrom returns the info of the current call, or a throwaway one
+// when the handler runs without the audit interceptor
+func requestInfoFrom(ctx context.Context) *requestInfo {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		return info
+	}
+	return &requestInfo{}
+}
+
+// statusMessage is implemented by every api response
+type statusMessage interface {
+	GetStatus() int32
+	GetError() string
+}
+
+// NewAuditInterceptor records every unary call in request_log with its
+// duration, outcome, serving node and cache source
+func NewAuditInterceptor(database *db.Database, logger *zap.SugaredLogger) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			info := &requestInfo{id: req.Header().Get(RequestIDHeader)}
+			if info.id == "" {
+				info.id = uuid.New().String()
+			}
+
+			started := time.Now()
+			resp, err := next(context.WithValue(ctx, requestInfoKey{}, info), req)
+			duration := time.Since(started).Milliseconds()
+
+			success := err == nil
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+				if info.errorCode == "" {
+					info.errorCode = connect.CodeOf(err).String()
+				}
+			} else if msg, ok := resp.Any().(statusMessage); ok && msg.GetStatus() != 200 {
+				success = false
+				errMsg = msg.GetError()
+				if info.errorCode == "" {
+					info.errorCode = statusErrorCode(msg.GetStatus())
+				}
+			}
+
+			if resp != nil {
+				resp.Header().Set(RequestIDHeader, info.id)
+			}
+
+			logErr := database.LogRequest(context.WithoutCancel(ctx), db.LogRequestParams{
+				RequestID:    info.id,
+				Method:       path.Base(req.Spec().Procedure),
+				ClientID:     optional(info.clientID),
+				GameName:     info.name,
+				GameTag:      info.tag,
+				Puuid:        optional(info.puuid),
+				Source:       optional(info.source),
+				Success:      success,
+				ErrorCode:    optional(info.errorCode),
+				ErrorMessage: optional(errMsg),
+				DurationMs:   &duration,
+			})
+			if logErr != nil {
+				logger.Warnw("failed to write request log", "requestID", info.id, "error", logErr)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+func statusErrorCode(status int32) string {
+	switch status {
+	case 400:
+		return "BAD_REQUEST"
+	case 404:
+		return "NOT_FOUND"
+	default:
+		return "INTERNAL"
+	}
+}
+
+// optional maps empty strings to NULL
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+func (s *Service) GetNameHistory(
+	ctx context.Context,
+	req *connect.Request[v1.GetNameHistoryRequest],
+) (*connect.Response[v1.GetNameHistoryResponse], error) {
+	s.logger.Infow("get name history request", "puuid", req.Msg.Puuid, "name", req.Msg.Name, "tag", req.Msg.Tag)
+
+	puuid, status, errMsg := s.lookupPUUID(ctx, req.Msg.Puuid, req.Msg.Name, req.Msg.Tag)
+	if status != 200 {
+		return connect.NewResponse(&v1.GetNameHistoryResponse{
+			Status: status,
+			Error:  errMsg,
+		}), nil
+	}
+
+	aliases, err := s.db.GetAccountAliases(ctx, puuid)
+	if err != nil {
+		s.logger.Errorw("failed to get account aliases", "puuid", puuid, "error", err)
+		return connect.NewResponse(&v1.GetNameHistoryResponse{
+			Status: 500,
+			Error:  "failed to look up name history",
+		}), nil
+	}
+
+	if len(aliases) == 0 {
+		return connect.NewResponse(&v1.GetNameHistoryResponse{
+			Status: 404,
+			Error:  "no name history for this account",
+		}), nil
+	}
+
+	names := make([]*v1.NameHistoryEntry, 0, len(aliases))
+	for _, alias := range aliases {
+		names = append(names, &v1.NameHistoryEntry{
+			Name:      alias.Name,
+			Tag:       alias.Tag,
+			FirstSeen: alias.FirstSeen.Format(time.RFC3339),
+			LastSeen:  alias.LastSeen.Format(time.RFC3339),
+		})
+	}
+
+	return connect.NewResponse(&v1.GetNameHistoryResponse{
+		Status: 200,
+		Data: &v1.NameHistoryData{
+			Puuid: puuid,
+			Names: names,
+		},
+	}), nil
+}
+
+func (s *Service) GetAccountHistory(
+	ctx context.Context,
+	req *connect.Request[v1.GetAccountHistoryRequest],
+) (*connect.Response[v1.GetAccountHistoryResponse], error) {
+	s.logger.Infow("get account history request", "puuid", req.Msg.Puuid, "name", req.Msg.Name, "tag", req.Msg.Tag)
+
+	puuid, status, errMsg := s.lookupPUUID(ctx, req.Msg.Puuid, req.Msg.Name, req.Msg.Tag)
+	if status != 200 {
+		return connect.NewResponse(&v1.GetAccountHistoryResponse{
+			Status: status,
+			Error:  errMsg,
+		}), nil
+	}
+
+	limit := req.Msg.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	rows, err := s.db.GetAccountSnapshots(ctx, db.GetAccountSnapshotsParams{
+		Puuid: puuid,
+		Limit: int64(limit),
+	})
+	if err != nil {
+		s.logger.Errorw("failed to get account snapshots", "puuid", puuid, "error", err)
+		return connect.NewResponse(&v1.GetAccountHistoryResponse{
+			Status: 500,
+			Error:  "failed to look up account history",
+		}), nil
+	}
+
+	if len(rows) == 0 {
+		return connect.NewResponse(&v1.GetAccountHistoryResponse{
+			Status: 404,
+			Error:  "no history for this account",
+		}), nil
+	}
+
+	// rows come newest first so the limit keeps the most recent ones
+	snapshots := make([]*v1.AccountSnapshot, len(rows))
+	for i, row := range rows {
+		snapshots[len(rows)-1-i] = &v1.AccountSnapshot{
+			AccountLevel: int32(row.AccountLevel),
+			Card:         row.Card,
+			Title:        row.Title,
+			RecordedAt:   row.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return connect.NewResponse(&v1.GetAccountHistoryResponse{
+		Status: 200,
+		Data: &v1.AccountHistoryData{
+			Puuid:     puuid,
+			Snapshots: snapshots,
+		},
+	}), nil
+}
+
+// lookupPUUID returns the puuid as given or finds it through the riot ids the
+// account has been seen under, with a status and error message on failure
+func (s *Service) lookupPUUID(ctx context.Context, puuid, name, tag string) (string, int32, string) {
+	info := requestInfoFrom(ctx)
+	info.name = name
+	info.tag = tag
+	info.puuid = puuid
+	info.source = SourceDatabase
+
+	if puuid != "" {
+		return puuid, 200, ""
+	}
+
+	if name == "" || tag == "" {
+		return "", 400, "either puuid or name and tag are required"
+	}
+
+	puuid, err := s.db.GetAliasPUUIDByNameTag(ctx, db.GetAliasPUUIDByNameTagParams{
+		Name: name,
+		Tag:  tag,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 404, "no known account uses this riot id"
+	}
+	if err != nil {
+		s.logger.Errorw("failed to look up alias", "error", err)
+		return "", 500, "failed to look up account"
+	}
+
+	info.puuid = puuid
+	return puuid, 200, ""
+}
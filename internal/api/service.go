@@ -2,10 +2,16 @@ package api
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
 
 	"connectrpc.com/connect"
 	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
@@ -14,18 +20,50 @@ import (
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/protocol"
 )
 
+// NegativeLookup is a remembered lookup that a node reported as not found
+type NegativeLookup struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Freshness controls how long cached account data is served. data younger than
+// Fresh is served as is, data younger than Stale is served while a node
+// refreshes it in the background, anything older blocks on a node. failed
+// lookups are remembered for NotFound
+type Freshness struct {
+	Fresh    time.Duration
+	Stale    time.Duration
+	NotFound time.Duration
+}
+
+const (
+	SourceMemory   = "memory"
+	SourceDatabase = "database"
+	SourceNode     = "node"
+)
+
+// Nodes resolves accounts on the client nodes, a *protocol.Server
+type Nodes interface {
+	ResolveAccount(gameName, gameTag string) (*protocol.Response, error)
+}
+
 type Service struct {
-	tcpServer *protocol.Server
-	cache     *cache.Cache
+	tcpServer Nodes
+	accounts  cache.Cache[*v1.AccountData]
+	negatives cache.Cache[*NegativeLookup]
 	db        *db.Database
+	freshness Freshness
+	flights   singleflight.Group
 	logger    *zap.SugaredLogger
 }
 
-func NewService(tcpServer *protocol.Server, cache *cache.Cache, database *db.Database, logger *zap.SugaredLogger) *Service {
+func NewService(tcpServer Nodes, accounts cache.Cache[*v1.AccountData], negatives cache.Cache[*NegativeLookup], database *db.Database, freshness Freshness, logger *zap.SugaredLogger) *Service {
 	return &Service{
 		tcpServer: tcpServer,
-		cache:     cache,
+		accounts:  accounts,
+		negatives: negatives,
 		db:        database,
+		freshness: freshness,
 		logger:    logger,
 	}
 }
@@ -36,65 +74,244 @@ func (s *Service) GetAccount(
 ) (*connect.Response[v1.GetAccountResponse], error) {
 	name := req.Msg.Name
 	tag := req.Msg.Tag
+	maxAge := time.Duration(req.Msg.MaxAge) * time.Second
 
-	s.logger.Infow("get account request", "name", name, "tag", tag)
+	s.logger.Infow("get account request", "name", name, "tag", tag, "forceRefresh", req.Msg.ForceRefresh, "maxAge", maxAge)
+
+	info := requestInfoFrom(ctx)
+	info.name = name
+	info.tag = tag
+
+	if req.Msg.ForceRefresh {
+		s.forgetNegative(ctx, name, tag)
+	} else {
+		if negative, source, ok := s.getNegativeLookup(ctx, name, tag); ok {
+			s.logger.Debugw("negative cache hit", "source", source, "name", name, "tag", tag, "code", negative.Code)
+			info.source = source
+			info.errorCode = negative.Code
+			return connect.NewResponse(&v1.GetAccountResponse{
+				Status: 404,
+				Error:  negative.Message,
+				Source: source,
+			}), nil
+		}
+
+		if accountData, source, ok := s.getCachedAccount(ctx, name, tag); ok {
+			age := accountAge(accountData)
+			if s.isUsable(age, maxAge) {
+				s.logger.Debugw("cache hit", "source", source, "name", name, "tag", tag, "age", age)
+				info.source = source
+				info.puuid = accountData.Puuid
+
+				if age >= s.freshness.Fresh {
+					s.revalidate(name, tag)
+				}
+
+				return connect.NewResponse(&v1.GetAccountResponse{
+					Status: 200,
+					Data:   accountData,
+					Source: source,
+					Age:    int64(age / time.Second),
+				}), nil
+			}
+		}
+	}
+
+	s.logger.Debugw("cache miss, resolving via client", "name", name, "tag", tag)
+
+	info.source = SourceNode
+
+	resolved, err := s.resolveAccountShared(ctx, name, tag)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, contextError(ctx.Err())
+		}
+
+		status := int32(500)
+		var resolveErr *resolveError
+		if errors.As(err, &resolveErr) {
+			info.clientID = resolveErr.clientID
+			info.errorCode = resolveErr.code
+			if isNegativeCode(resolveErr.code) {
+				status = 404
+			}
+			if resolveErr.code == protocol.ErrorCodeRateLimited {
+				status = 429
+			}
+		}
 
-	cacheKey := cache.MakeKey(name, tag)
-	if cached, ok := s.cache.Get(cacheKey); ok {
-		s.logger.Debugw("cache hit (memory)", "name", name, "tag", tag)
-		accountData := cached.(*v1.AccountData)
 		return connect.NewResponse(&v1.GetAccountResponse{
-			Status: 200,
-			Data:   accountData,
+			Status: status,
+			Error:  err.Error(),
+			Source: SourceNode,
 		}), nil
 	}
 
+	info.clientID = resolved.clientID
+	info.puuid = resolved.account.Puuid
+
+	return connect.NewResponse(&v1.GetAccountResponse{
+		Status: 200,
+		Data:   resolved.account,
+		Source: SourceNode,
+		Age:    int64(accountAge(resolved.account) / time.Second),
+	}), nil
+}
+
+func (s *Service) getCachedAccount(ctx context.Context, name, tag string) (*v1.AccountData, string, bool) {
+	cacheKey := cache.MakeKey(name, tag)
+	if cached, ok := s.accounts.Get(cacheKey); ok {
+		return cached, SourceMemory, true
+	}
+
 	dbAccount, err := s.db.GetAccountByNameTag(ctx, db.GetAccountByNameTagParams{
 		Name: name,
 		Tag:  tag,
 	})
+	if err != nil {
+		return nil, "", false
+	}
 
-	if err == nil {
-		if time.Since(dbAccount.UpdatedAt) < 1*time.Hour {
-			s.logger.Debug("cache hit (database)", "name", name, "tag", tag)
-
-			accountData := &v1.AccountData{
-				Puuid:        dbAccount.Puuid,
-				Region:       dbAccount.Region,
-				AccountLevel: int32(dbAccount.AccountLevel),
-				Name:         dbAccount.Name,
-				Tag:          dbAccount.Tag,
-				Card:         dbAccount.Card,
-				Title:        dbAccount.Title,
-				UpdatedAt:    dbAccount.UpdatedAt.Format(time.RFC3339),
-			}
+	accountData := &v1.AccountData{
+		Puuid:        dbAccount.Puuid,
+		Region:       dbAccount.Region,
+		AccountLevel: int32(dbAccount.AccountLevel),
+		Name:         dbAccount.Name,
+		Tag:          dbAccount.Tag,
+		Card:         dbAccount.Card,
+		Title:        dbAccount.Title,
+		UpdatedAt:    dbAccount.UpdatedAt.Format(time.RFC3339),
+	}
+
+	// a row past the stale window is refused by every request, caching it
+	// would only evict entries that can still be served. a usable one is
+	// cached until it turns unusable
+	if age := accountAge(accountData); s.isUsable(age, 0) {
+		s.accounts.SetWithTTL(cacheKey, accountData, s.freshness.Stale-age)
+	}
 
-			s.cache.Set(cacheKey, accountData)
+	return accountData, SourceDatabase, true
+}
 
-			return connect.NewResponse(&v1.GetAccountResponse{
-				Status: 200,
-				Data:   accountData,
-			}), nil
-		}
+func (s *Service) getNegativeLookup(ctx context.Context, name, tag string) (*NegativeLookup, string, bool) {
+	cacheKey := cache.MakeKey(name, tag)
+	if cached, ok := s.negatives.Get(cacheKey); ok {
+		return cached, SourceMemory, true
 	}
 
-	s.logger.Debugw("cache miss, resolving via client", "name", name, "tag", tag)
+	row, err := s.db.GetNegativeLookup(ctx, db.GetNegativeLookupParams{
+		Name: name,
+		Tag:  tag,
+	})
+	if err != nil {
+		return nil, "", false
+	}
+	// a ttl of 0 would mean the cache default, expired rows must stay out
+	ttl := time.Until(row.ExpiresAt)
+	if ttl <= 0 {
+		return nil, "", false
+	}
+
+	negative := &NegativeLookup{Code: row.ErrorCode, Message: row.ErrorMessage}
+	s.negatives.SetWithTTL(cacheKey, negative, ttl)
+
+	return negative, SourceDatabase, true
+}
+
+func (s *Service) rememberNegative(ctx context.Context, name, tag, code, message string) {
+	s.negatives.SetWithTTL(cache.MakeKey(name, tag), &NegativeLookup{Code: code, Message: message}, s.freshness.NotFound)
+
+	err := s.db.UpsertNegativeLookup(ctx, db.UpsertNegativeLookupParams{
+		Name:         name,
+		Tag:          tag,
+		ErrorCode:    code,
+		ErrorMessage: message,
+		ExpiresAt:    time.Now().Add(s.freshness.NotFound).UTC(),
+	})
+	if err != nil {
+		s.logger.Warnw("failed to store negative lookup in database", "error", err)
+	}
+}
+
+func (s *Service) forgetNegative(ctx context.Context, name, tag string) {
+	s.negatives.Delete(cache.MakeKey(name, tag))
 
+	err := s.db.DeleteNegativeLookup(ctx, db.DeleteNegativeLookupParams{
+		Name: name,
+		Tag:  tag,
+	})
+	if err != nil {
+		s.logger.Warnw("failed to delete negative lookup from database", "error", err)
+	}
+}
+
+// isUsable reports whether cached data of the given age can be served, maxAge
+// from the request tightens the stale window but never widens it
+func (s *Service) isUsable(age, maxAge time.Duration) bool {
+	if maxAge > 0 && age > maxAge {
+		return false
+	}
+	return age < s.freshness.Stale
+}
+
+// revalidate refreshes an account in the background, joining any resolve
+// already in flight for the same riot id
+func (s *Service) revalidate(name, tag string) {
+	s.logger.Debugw("serving stale account, refreshing in background", "name", name, "tag", tag)
+	s.flights.DoChan(accountFlightKey(name, tag), func() (interface{}, error) {
+		return s.resolveAccount(context.Background(), name, tag)
+	})
+}
+
+// resolveAccountShared coalesces concurrent lookups of the same riot id into one
+// node resolve. the resolve runs detached from the caller so a cancelled leader
+// doesn't fail the other waiters, each caller only stops waiting on its own ctx
+func (s *Service) resolveAccountShared(ctx context.Context, name, tag string) (*resolution, error) {
+	ch := s.flights.DoChan(accountFlightKey(name, tag), func() (interface{}, error) {
+		return s.resolveAccount(context.WithoutCancel(ctx), name, tag)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Shared {
+			s.logger.Debugw("shared in-flight resolve", "name", name, "tag", tag)
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*resolution), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Service) resolveAccount(ctx context.Context, name, tag string) (*resolution, error) {
 	response, err := s.tcpServer.ResolveAccount(name, tag)
 	if err != nil {
 		s.logger.Errorw("failed to resolve account", "error", err)
-		return connect.NewResponse(&v1.GetAccountResponse{
-			Status: 500,
-			Error:  fmt.Sprintf("failed to resolve account: %v", err),
-		}), nil
+
+		resolveErr := &resolveError{
+			code:    protocol.ErrorCodeResolveFailed,
+			message: fmt.Sprintf("failed to resolve account: %v", err),
+		}
+		switch {
+		case errors.Is(err, protocol.ErrNoAvailableClients):
+			resolveErr.code = protocol.ErrorCodeNoClients
+		case errors.Is(err, protocol.ErrRequestTimeout):
+			resolveErr.code = protocol.ErrorCodeTimeout
+		}
+		if response != nil {
+			resolveErr.clientID = response.ClientID
+		}
+		return nil, resolveErr
 	}
 
 	if response.Error != "" {
-		s.logger.Errorw("client returned error", "error", response.Error)
-		return connect.NewResponse(&v1.GetAccountResponse{
-			Status: 500,
-			Error:  response.Error,
-		}), nil
+		s.logger.Errorw("client returned error", "error", response.Error, "code", response.ErrorCode, "clientID", response.ClientID)
+		if isNegativeCode(response.ErrorCode) {
+			s.rememberNegative(ctx, name, tag, response.ErrorCode, response.Error)
+		}
+		return nil, &resolveError{code: response.ErrorCode, message: response.Error, clientID: response.ClientID}
 	}
 
 	now := time.Now().Format(time.RFC3339)
@@ -109,26 +326,130 @@ func (s *Service) GetAccount(
 		UpdatedAt:    now,
 	}
 
-	s.cache.Set(cacheKey, accountData)
+	s.accounts.Set(cache.MakeKey(name, tag), accountData)
+	s.negatives.Delete(cache.MakeKey(name, tag))
 
-	err = s.db.UpsertAccount(ctx, db.UpsertAccountParams{
+	previous, err := s.db.GetAccountByPUUID(ctx, response.PUUID)
+	if err == nil && (!strings.EqualFold(previous.Name, name) || !strings.EqualFold(previous.Tag, tag)) {
+		s.logger.Infow("account name change detected", "puuid", response.PUUID, "oldName", previous.Name, "oldTag", previous.Tag, "name", name, "tag", tag)
+		s.accounts.Delete(cache.MakeKey(previous.Name, previous.Tag))
+	}
+
+	err = s.db.InTx(ctx, func(q db.Querier) error {
+		err := q.UpsertAccount(ctx, db.UpsertAccountParams{
+			Puuid:        response.PUUID,
+			Region:       response.Region,
+			AccountLevel: int64(response.AccountLevel),
+			Name:         name,
+			Tag:          tag,
+			Card:         response.Card,
+			Title:        response.Title,
+		})
+		if err != nil {
+			return err
+		}
+
+		// the riot id exists now, whatever casing it was last missed under
+		err = q.DeleteNegativeLookup(ctx, db.DeleteNegativeLookupParams{
+			Name: name,
+			Tag:  tag,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.UpsertAccountAlias(ctx, db.UpsertAccountAliasParams{
+			Puuid: response.PUUID,
+			Name:  name,
+			Tag:   tag,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordSnapshot(ctx, q, response)
+	})
+
+	if err != nil {
+		s.logger.Warnw("failed to store account in database", "error", err)
+	}
+
+	s.logger.Infow("account resolved successfully", "puuid", response.PUUID, "clientID", response.ClientID)
+
+	return &resolution{account: accountData, clientID: response.ClientID}, nil
+}
+
+// recordSnapshot appends a snapshot when level, card or title changed since the last one
+func recordSnapshot(ctx context.Context, q db.Querier, response *protocol.Response) error {
+	latest, err := q.GetLatestAccountSnapshot(ctx, response.PUUID)
+	if err == nil && latest.AccountLevel == int64(response.AccountLevel) && latest.Card == response.Card && latest.Title == response.Title {
+		return nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	return q.InsertAccountSnapshot(ctx, db.InsertAccountSnapshotParams{
 		Puuid:        response.PUUID,
-		Region:       response.Region,
 		AccountLevel: int64(response.AccountLevel),
-		Name:         name,
-		Tag:          tag,
 		Card:         response.Card,
 		Title:        response.Title,
 	})
+}
+
+// resolution is a successful node resolve and the node that served it
+type resolution struct {
+	account  *v1.AccountData
+	clientID string
+}
 
+// resolveError is a failed node resolve with its error code and the node
+// that was asked, if any
+type resolveError struct {
+	code     string
+	message  string
+	clientID string
+}
+
+func (e *resolveError) Error() string {
+	return e.message
+}
+
+// rough per entry overhead of the cache bookkeeping and struct headers
+const cacheEntryOverhead = 128
+
+// AccountCacheSize estimates the memory used by a cached account
+func AccountCacheSize(key string, accountData *v1.AccountData) int64 {
+	return int64(len(key) + proto.Size(accountData) + cacheEntryOverhead)
+}
+
+// NegativeCacheSize estimates the memory used by a cached negative lookup
+func NegativeCacheSize(key string, negative *NegativeLookup) int64 {
+	return int64(len(key) + len(negative.Code) + len(negative.Message) + cacheEntryOverhead)
+}
+
+func isNegativeCode(code string) bool {
+	return code == protocol.ErrorCodeNotFound || code == protocol.ErrorCodeNoMatchHistory
+}
+
+// accountAge treats an unreadable timestamp as infinitely old so the account
+// is resolved again rather than served as fresh
+func accountAge(accountData *v1.AccountData) time.Duration {
+	updatedAt, err := time.Parse(time.RFC3339, accountData.UpdatedAt)
 	if err != nil {
-		s.logger.Warnw("failed to store account in database", "error", err)
+		return math.MaxInt64
 	}
+	return time.Since(updatedAt)
+}
 
-	s.logger.Infow("account resolved successfully", "puuid", response.PUUID)
+// lookups differing only in case share a flight
+func accountFlightKey(name, tag string) string {
+	return "account:" + cache.MakeKey(name, tag)
+}
 
-	return connect.NewResponse(&v1.GetAccountResponse{
-		Status: 200,
-		Data:   accountData,
-	}), nil
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	}
+	return connect.NewError(connect.CodeCanceled, err)
 }
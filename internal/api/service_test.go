@@ -0,0 +1,360 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"connectrpc.com/connect"
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/cache"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/protocol"
+)
+
+// stubNodes counts resolves and, when gate is set, holds each one until the
+// gate is closed. while missing is set every riot id is reported not found
+type stubNodes struct {
+	mu      sync.Mutex
+	calls   int
+	missing bool
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func newStubNodes() *stubNodes {
+	return &stubNodes{started: make(chan struct{}, 100)}
+}
+
+func (n *stubNodes) ResolveAccount(name, tag string) (*protocol.Response, error) {
+	n.mu.Lock()
+	n.calls++
+	missing := n.missing
+	n.mu.Unlock()
+
+	n.started <- struct{}{}
+	if n.gate != nil {
+		<-n.gate
+	}
+	if missing {
+		return &protocol.Response{ClientID: "node-1", Error: "account not found", ErrorCode: protocol.ErrorCodeNotFound}, nil
+	}
+	return &protocol.Response{ClientID: "node-1", PUUID: "puuid-1", Region: "eu1", AccountLevel: 42}, nil
+}
+
+func (n *stubNodes) setMissing(missing bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.missing = missing
+}
+
+func (n *stubNodes) Calls() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls
+}
+
+func newTestService(t *testing.T, nodes Nodes) (*Service, *db.Database) {
+	t.Helper()
+
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	accounts := cache.NewMemory(cache.MemoryOptions[*v1.AccountData]{TTL: time.Hour})
+	negatives := cache.NewMemory(cache.MemoryOptions[*NegativeLookup]{TTL: time.Hour})
+	t.Cleanup(accounts.Stop)
+	t.Cleanup(negatives.Stop)
+
+	freshness := Freshness{Fresh: time.Hour, Stale: 24 * time.Hour, NotFound: 10 * time.Minute}
+	return NewService(nodes, accounts, negatives, database, freshness, zap.NewNop().Sugar()), database
+}
+
+func getAccount(ctx context.Context, s *Service, name, tag string) (*v1.GetAccountResponse, error) {
+	resp, err := s.GetAccount(ctx, connect.NewRequest(&v1.GetAccountRequest{Name: name, Tag: tag}))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Msg, nil
+}
+
+func TestConcurrentLookupsCoalesce(t *testing.T) {
+	nodes := newStubNodes()
+	nodes.gate = make(chan struct{})
+	s, _ := newTestService(t, nodes)
+
+	const lookups = 10
+	var wg sync.WaitGroup
+	responses := make([]*v1.GetAccountResponse, lookups)
+	errs := make([]error, lookups)
+	for i := range lookups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// riot ids differing in case share the flight
+			name := "Player"
+			if i%2 == 1 {
+				name = "PLAYER"
+			}
+			responses[i], errs[i] = getAccount(context.Background(), s, name, "EUW")
+		}()
+	}
+
+	<-nodes.started
+	// give the other lookups time to miss the cache and join the flight
+	time.Sleep(50 * time.Millisecond)
+	close(nodes.gate)
+	wg.Wait()
+
+	for i := range lookups {
+		if errs[i] != nil {
+			t.Fatalf("lookup %d failed: %v", i, errs[i])
+		}
+		if responses[i].Status != 200 || responses[i].Data.Puuid != "puuid-1" {
+			t.Errorf("lookup %d got status %d: %+v", i, responses[i].Status, responses[i].Data)
+		}
+	}
+	if calls := nodes.Calls(); calls != 1 {
+		t.Errorf("nodes resolved %d times, want 1", calls)
+	}
+}
+
+func TestCancelledLookupKeepsFlight(t *testing.T) {
+	nodes := newStubNodes()
+	nodes.gate = make(chan struct{})
+	s, database := newTestService(t, nodes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := getAccount(ctx, s, "Player", "EUW")
+		cancelled <- err
+	}()
+	<-nodes.started
+
+	waiting := make(chan *v1.GetAccountResponse, 1)
+	go func() {
+		resp, err := getAccount(context.Background(), s, "Player", "EUW")
+		if err != nil {
+			t.Errorf("second lookup failed: %v", err)
+		}
+		waiting <- resp
+	}()
+
+	// the leader gives up, the resolve it started must carry on
+	cancel()
+	err := <-cancelled
+	if connect.CodeOf(err) != connect.CodeCanceled {
+		t.Errorf("cancelled lookup returned %v, want %v", err, connect.CodeCanceled)
+	}
+
+	close(nodes.gate)
+	if resp := <-waiting; resp == nil || resp.Status != 200 {
+		t.Fatalf("waiting lookup got %+v", resp)
+	}
+	if calls := nodes.Calls(); calls != 1 {
+		t.Errorf("nodes resolved %d times, want 1", calls)
+	}
+
+	// the result was stored even though its leader was gone
+	if _, err := database.GetAccountByPUUID(context.Background(), "puuid-1"); err != nil {
+		t.Errorf("account not stored: %v", err)
+	}
+}
+
+func TestStaleRowNotCached(t *testing.T) {
+	nodes := newStubNodes()
+	s, database := newTestService(t, nodes)
+	ctx := context.Background()
+
+	old := time.Now().Add(-48 * time.Hour)
+	err := database.ImportAccount(ctx, db.ImportAccountParams{
+		Puuid: "puuid-1", Region: "eu1", AccountLevel: 10, Name: "Player", Tag: "EUW", UpdatedAt: old, CreatedAt: old,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, ok := s.getCachedAccount(ctx, "Player", "EUW"); !ok {
+		t.Fatal("row not found")
+	}
+	if _, ok := s.accounts.Get(cache.MakeKey("Player", "EUW")); ok {
+		t.Error("row past the stale window was cached")
+	}
+
+	resp, err := getAccount(ctx, s, "Player", "EUW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Source != SourceNode || resp.Data.AccountLevel != 42 {
+		t.Errorf("stale row served from %s: %+v", resp.Source, resp.Data)
+	}
+	if calls := nodes.Calls(); calls != 1 {
+		t.Errorf("nodes resolved %d times, want 1", calls)
+	}
+}
+
+// ttlCache records the ttl of every entry set
+type ttlCache struct {
+	cache.Cache[*v1.AccountData]
+	ttls map[string]time.Duration
+}
+
+func (c *ttlCache) SetWithTTL(key string, value *v1.AccountData, ttl time.Duration) {
+	c.ttls[key] = ttl
+	c.Cache.SetWithTTL(key, value, ttl)
+}
+
+func TestStaleRowCachedUntilUnusable(t *testing.T) {
+	s, database := newTestService(t, newStubNodes())
+	accounts := &ttlCache{Cache: s.accounts, ttls: make(map[string]time.Duration)}
+	s.accounts = accounts
+	ctx := context.Background()
+
+	// past the fresh window but still servable for another 22 hours
+	old := time.Now().Add(-2 * time.Hour)
+	err := database.ImportAccount(ctx, db.ImportAccountParams{
+		Puuid: "puuid-1", Region: "eu1", AccountLevel: 10, Name: "Player", Tag: "EUW", UpdatedAt: old, CreatedAt: old,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, source, ok := s.getCachedAccount(ctx, "Player", "EUW"); !ok || source != SourceDatabase {
+		t.Fatalf("got source %q, want the row from the database", source)
+	}
+	ttl, ok := accounts.ttls[cache.MakeKey("Player", "EUW")]
+	if !ok || ttl < 21*time.Hour || ttl > 22*time.Hour {
+		t.Errorf("cached for %v, want until the stale window ends in 22h", ttl)
+	}
+}
+
+func TestAccountAge(t *testing.T) {
+	tests := []struct {
+		name      string
+		updatedAt string
+		min, max  time.Duration
+	}{
+		{"recent", time.Now().Add(-time.Minute).Format(time.RFC3339), 59 * time.Second, 2 * time.Minute},
+		{"unparseable", "yesterday", math.MaxInt64, math.MaxInt64},
+		{"empty", "", math.MaxInt64, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			age := accountAge(&v1.AccountData{UpdatedAt: tt.updatedAt})
+			if age < tt.min || age > tt.max {
+				t.Errorf("age = %v, want between %v and %v", age, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRenameUnderDifferentCasing(t *testing.T) {
+	nodes := newStubNodes()
+	s, _ := newTestService(t, nodes)
+	ctx := context.Background()
+
+	lookup := func(name, tag, wantSource string) {
+		t.Helper()
+		resp, err := getAccount(ctx, s, name, tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != 200 || resp.Source != wantSource {
+			t.Fatalf("%s#%s got status %d from %s, want 200 from %s", name, tag, resp.Status, resp.Source, wantSource)
+		}
+	}
+
+	lookup("Player", "EUW", SourceNode)
+	lookup("pLAYER", "eUW", SourceMemory)
+
+	// the stub answers every riot id with the same puuid, so this is a rename
+	lookup("newname", "euw", SourceNode)
+	for _, id := range [][2]string{{"Player", "EUW"}, {"PLAYER", "euw"}, {"player", "euw"}} {
+		if _, ok := s.accounts.Get(cache.MakeKey(id[0], id[1])); ok {
+			t.Errorf("old riot id %s#%s still cached", id[0], id[1])
+		}
+	}
+	lookup("NewName", "EUW", SourceMemory)
+
+	if calls := nodes.Calls(); calls != 2 {
+		t.Errorf("nodes resolved %d times, want 2", calls)
+	}
+}
+
+func TestNotFoundClearedUnderDifferentCasing(t *testing.T) {
+	nodes := newStubNodes()
+	nodes.missing = true
+	s, database := newTestService(t, nodes)
+	ctx := context.Background()
+
+	resp, err := getAccount(ctx, s, "foo", "na1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != 404 {
+		t.Fatalf("got status %d, want 404", resp.Status)
+	}
+
+	// the negative is only in the database, as after a restart
+	s.negatives.Delete(cache.MakeKey("foo", "na1"))
+	if resp, err := getAccount(ctx, s, "FOO", "NA1"); err != nil || resp.Status != 404 || resp.Source != SourceDatabase {
+		t.Fatalf("stored 404 not found under another casing: %+v, %v", resp, err)
+	}
+
+	// the account is created and looked up again with a forced refresh
+	nodes.setMissing(false)
+	refreshed, err := s.GetAccount(ctx, connect.NewRequest(&v1.GetAccountRequest{Name: "Foo", Tag: "NA1", ForceRefresh: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.Msg.Status != 200 {
+		t.Fatalf("forced refresh got status %d: %s", refreshed.Msg.Status, refreshed.Msg.Error)
+	}
+
+	if _, err := database.GetNegativeLookup(ctx, db.GetNegativeLookupParams{Name: "foo", Tag: "na1"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("negative lookup still stored: %v", err)
+	}
+	for _, id := range [][2]string{{"foo", "na1"}, {"FOO", "na1"}} {
+		resp, err := getAccount(ctx, s, id[0], id[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != 200 || resp.Data.Puuid != "puuid-1" {
+			t.Errorf("%s#%s got status %d from %s, want the account", id[0], id[1], resp.Status, resp.Source)
+		}
+	}
+	if calls := nodes.Calls(); calls != 2 {
+		t.Errorf("nodes resolved %d times, want 2", calls)
+	}
+}
+
+func TestResolveClearsNegative(t *testing.T) {
+	nodes := newStubNodes()
+	s, database := newTestService(t, nodes)
+	ctx := context.Background()
+
+	// a 404 remembered under one casing, then resolved under another
+	s.rememberNegative(ctx, "foo", "na1", protocol.ErrorCodeNotFound, "account not found")
+	if _, err := s.resolveAccount(ctx, "Foo", "NA1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.negatives.Get(cache.MakeKey("foo", "na1")); ok {
+		t.Error("negative lookup still cached")
+	}
+	if _, err := database.GetNegativeLookup(ctx, db.GetNegativeLookupParams{Name: "FOO", Tag: "na1"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("negative lookup still stored: %v", err)
+	}
+	if resp, err := getAccount(ctx, s, "foo", "na1"); err != nil || resp.Status != 200 {
+		t.Errorf("lookup after resolve: %+v, %v", resp, err)
+	}
+}
@@ -1,89 +1,37 @@
 package cache
 
 import (
-	"sync"
+	"strings"
 	"time"
 )
 
-type Item struct {
-	Value      interface{}
-	Expiration int64
-}
-
-type Cache struct {
-	mu    sync.RWMutex
-	items map[string]*Item
-	ttl   time.Duration
-}
-
-func New(ttl time.Duration) *Cache {
-	c := &Cache{
-		items: make(map[string]*Item),
-		ttl:   ttl,
-	}
-
-	// Start cleanup goroutine
-	go c.cleanupLoop()
-
-	return c
-}
-
-func (c *Cache) Set(key string, value interface{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	expiration := time.Now().Add(c.ttl).UnixNano()
-	c.items[key] = &Item{
-		Value:      value,
-		Expiration: expiration,
-	}
-}
-
-func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	item, exists := c.items[key]
-	if !exists {
-		return nil, false
-	}
-
-	// Check if expired
-	if time.Now().UnixNano() > item.Expiration {
-		return nil, false
-	}
-
-	return item.Value, true
-}
-
-func (c *Cache) Delete(key string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	delete(c.items, key)
-}
-
-func (c *Cache) cleanupLoop() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		c.cleanup()
-	}
-}
-
-func (c *Cache) cleanup() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	now := time.Now().UnixNano()
-	for key, item := range c.items {
-		if now > item.Expiration {
-			delete(c.items, key)
-		}
-	}
-}
+// Cache is implemented by every cache backend
+type Cache[V any] interface {
+	Get(key string) (V, bool)
+	Set(key string, value V)
+	SetWithTTL(key string, value V, ttl time.Duration)
+	Delete(key string)
+	Stats() Stats
+	Stop()
+}
+
+type Stats struct {
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	Errors      uint64 `json:"errors"`
+	Entries     int    `json:"entries"`
+	Bytes       int64  `json:"bytes"`
+}
+
+var (
+	_ Cache[any] = (*Memory[any])(nil)
+	_ Cache[any] = (*Redis[any])(nil)
+)
 
+// MakeKey builds the cache key of a riot id. riot ids are case insensitive so
+// every casing shares one entry
 func MakeKey(name, tag string) string {
-	return name + "#" + tag
+	return strings.ToLower(strings.TrimSpace(name)) + "#" + strings.ToLower(strings.TrimSpace(tag))
 }
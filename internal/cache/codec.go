@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec serializes values for backends that store bytes
+type Codec[V any] interface {
+	Marshal(value V) ([]byte, error)
+	Unmarshal(data []byte) (V, error)
+}
+
+type protoCodec[V proto.Message] struct {
+	newValue func() V
+}
+
+// ProtoCodec encodes protobuf messages in their binary wire format
+func ProtoCodec[V proto.Message](newValue func() V) Codec[V] {
+	return protoCodec[V]{newValue: newValue}
+}
+
+func (c protoCodec[V]) Marshal(value V) ([]byte, error) {
+	return proto.Marshal(value)
+}
+
+func (c protoCodec[V]) Unmarshal(data []byte) (V, error) {
+	value := c.newValue()
+	if err := proto.Unmarshal(data, value); err != nil {
+		var zero V
+		return zero, err
+	}
+	return value, nil
+}
+
+type jsonCodec[V any] struct{}
+
+// JSONCodec encodes plain structs as json
+func JSONCodec[V any]() Codec[V] {
+	return jsonCodec[V]{}
+}
+
+func (jsonCodec[V]) Marshal(value V) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (jsonCodec[V]) Unmarshal(data []byte) (V, error) {
+	var value V
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
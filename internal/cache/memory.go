@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type MemoryOptions[V any] struct {
+	// TTL is the lifetime of entries stored with Set
+	TTL time.Duration
+	// MaxEntries bounds the number of entries, 0 means unbounded
+	MaxEntries int
+	// MaxBytes bounds the summed Size of all entries, 0 means unbounded
+	MaxBytes int64
+	// Size estimates how much memory an entry uses, only needed with MaxBytes
+	Size func(key string, value V) int64
+	// CleanupInterval is how often expired entries are dropped, defaults to 1 minute
+	CleanupInterval time.Duration
+}
+
+type entry[V any] struct {
+	key        string
+	value      V
+	size       int64
+	expiration int64
+}
+
+// Memory is an in-process LRU cache with per-entry expiration, bounded by entry
+// count and estimated size
+type Memory[V any] struct {
+	mu    sync.Mutex
+	items map[string]*list.Element
+	lru   *list.List
+	opts  MemoryOptions[V]
+	bytes int64
+	stats Stats
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewMemory[V any](opts MemoryOptions[V]) *Memory[V] {
+	if opts.CleanupInterval <= 0 {
+		opts.CleanupInterval = 1 * time.Minute
+	}
+
+	c := &Memory[V]{
+		items: make(map[string]*list.Element),
+		lru:   list.New(),
+		opts:  opts,
+		done:  make(chan struct{}),
+	}
+
+	go c.cleanupLoop()
+
+	return c
+}
+
+func (c *Memory[V]) Set(key string, value V) {
+	c.SetWithTTL(key, value, c.opts.TTL)
+}
+
+// SetWithTTL stores a value that expires after ttl instead of the cache
+// default, a ttl of 0 or less falls back to the default
+func (c *Memory[V]) SetWithTTL(key string, value V, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.opts.TTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var size int64
+	if c.opts.Size != nil {
+		size = c.opts.Size(key, value)
+	}
+
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		// would evict everything else and still not fit
+		if elem, exists := c.items[key]; exists {
+			c.removeElement(elem)
+		}
+		return
+	}
+
+	expiration := time.Now().Add(ttl).UnixNano()
+	if elem, exists := c.items[key]; exists {
+		e := elem.Value.(*entry[V])
+		c.bytes += size - e.size
+		e.value = value
+		e.size = size
+		e.expiration = expiration
+		c.lru.MoveToFront(elem)
+	} else {
+		c.items[key] = c.lru.PushFront(&entry[V]{
+			key:        key,
+			value:      value,
+			size:       size,
+			expiration: expiration,
+		})
+		c.bytes += size
+	}
+
+	c.evict()
+}
+
+func (c *Memory[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+
+	elem, exists := c.items[key]
+	if !exists {
+		c.stats.Misses++
+		return zero, false
+	}
+
+	e := elem.Value.(*entry[V])
+	if time.Now().UnixNano() > e.expiration {
+		c.removeElement(elem)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return zero, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.stats.Hits++
+
+	return e.value, true
+}
+
+func (c *Memory[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.items[key]; exists {
+		c.removeElement(elem)
+	}
+}
+
+func (c *Memory[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.items)
+	stats.Bytes = c.bytes
+	return stats
+}
+
+// Stop stops the cleanup goroutine, the cache stays usable afterwards
+func (c *Memory[V]) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// evict drops least recently used entries until the cache is within its bounds
+func (c *Memory[V]) evict() {
+	for c.overLimit() {
+		oldest := c.lru.Back()
+		if oldest == nil {
+			return
+		}
+		c.removeElement(oldest)
+		c.stats.Evictions++
+	}
+}
+
+func (c *Memory[V]) overLimit() bool {
+	if c.opts.MaxEntries > 0 && len(c.items) > c.opts.MaxEntries {
+		return true
+	}
+	return c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes
+}
+
+func (c *Memory[V]) removeElement(elem *list.Element) {
+	e := c.lru.Remove(elem).(*entry[V])
+	delete(c.items, e.key)
+	c.bytes -= e.size
+}
+
+func (c *Memory[V]) cleanupLoop() {
+	ticker := time.NewTicker(c.opts.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Memory[V]) cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	for _, elem := range c.items {
+		if now > elem.Value.(*entry[V]).expiration {
+			c.removeElement(elem)
+			c.stats.Expirations++
+		}
+	}
+}
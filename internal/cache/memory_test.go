@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	size := func(key, value string) int64 { return int64(len(value)) }
+
+	tests := []struct {
+		name    string
+		opts    MemoryOptions[string]
+		run     func(c *Memory[string])
+		present []string
+		absent  []string
+		stats   Stats
+	}{
+		{
+			name: "evicts least recently used",
+			opts: MemoryOptions[string]{TTL: time.Minute, MaxEntries: 2},
+			run: func(c *Memory[string]) {
+				c.Set("a", "1")
+				c.Set("b", "2")
+				c.Get("a")
+				c.Set("c", "3")
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+			stats:   Stats{Hits: 3, Misses: 1, Evictions: 1, Entries: 2},
+		},
+		{
+			name: "updating an entry refreshes it",
+			opts: MemoryOptions[string]{TTL: time.Minute, MaxEntries: 2},
+			run: func(c *Memory[string]) {
+				c.Set("a", "1")
+				c.Set("b", "2")
+				c.Set("a", "3")
+				c.Set("c", "4")
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+			stats:   Stats{Hits: 2, Misses: 1, Evictions: 1, Entries: 2},
+		},
+		{
+			name: "stays within max bytes",
+			opts: MemoryOptions[string]{TTL: time.Minute, MaxBytes: 10, Size: size},
+			run: func(c *Memory[string]) {
+				c.Set("a", "aaaa")
+				c.Set("b", "bbbb")
+				c.Set("c", "cccc")
+			},
+			present: []string{"b", "c"},
+			absent:  []string{"a"},
+			stats:   Stats{Hits: 2, Misses: 1, Evictions: 1, Entries: 2, Bytes: 8},
+		},
+		{
+			name: "growing an entry evicts others",
+			opts: MemoryOptions[string]{TTL: time.Minute, MaxBytes: 10, Size: size},
+			run: func(c *Memory[string]) {
+				c.Set("a", "aaaa")
+				c.Set("b", "bbbb")
+				c.Set("b", "bbbbbbbb")
+			},
+			present: []string{"b"},
+			absent:  []string{"a"},
+			stats:   Stats{Hits: 1, Misses: 1, Evictions: 1, Entries: 1, Bytes: 8},
+		},
+		{
+			name: "drops values larger than max bytes",
+			opts: MemoryOptions[string]{TTL: time.Minute, MaxBytes: 10, Size: size},
+			run: func(c *Memory[string]) {
+				c.Set("a", "aaaa")
+				c.Set("b", "bbbb")
+				c.Set("b", "bbbbbbbbbbbb")
+			},
+			present: []string{"a"},
+			absent:  []string{"b"},
+			stats:   Stats{Hits: 1, Misses: 1, Entries: 1, Bytes: 4},
+		},
+		{
+			name: "expires entries",
+			opts: MemoryOptions[string]{TTL: time.Minute},
+			run: func(c *Memory[string]) {
+				c.SetWithTTL("a", "1", time.Millisecond)
+				c.Set("b", "2")
+				time.Sleep(5 * time.Millisecond)
+			},
+			present: []string{"b"},
+			absent:  []string{"a"},
+			stats:   Stats{Hits: 1, Misses: 1, Expirations: 1, Entries: 1},
+		},
+		{
+			name: "zero ttl uses the default",
+			opts: MemoryOptions[string]{TTL: time.Minute},
+			run: func(c *Memory[string]) {
+				c.SetWithTTL("a", "1", 0)
+				time.Sleep(5 * time.Millisecond)
+			},
+			present: []string{"a"},
+			stats:   Stats{Hits: 1, Entries: 1},
+		},
+		{
+			name: "deletes entries",
+			opts: MemoryOptions[string]{TTL: time.Minute, Size: size},
+			run: func(c *Memory[string]) {
+				c.Set("a", "aaaa")
+				c.Set("b", "bbbb")
+				c.Delete("a")
+				c.Delete("missing")
+			},
+			present: []string{"b"},
+			absent:  []string{"a"},
+			stats:   Stats{Hits: 1, Misses: 1, Entries: 1, Bytes: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewMemory(tt.opts)
+			defer c.Stop()
+
+			tt.run(c)
+			for _, key := range tt.absent {
+				if _, ok := c.Get(key); ok {
+					t.Errorf("%s is cached", key)
+				}
+			}
+			for _, key := range tt.present {
+				if _, ok := c.Get(key); !ok {
+					t.Errorf("%s is not cached", key)
+				}
+			}
+			if stats := c.Stats(); stats != tt.stats {
+				t.Errorf("stats = %+v, want %+v", stats, tt.stats)
+			}
+		})
+	}
+}
+
+func TestMemoryCleanup(t *testing.T) {
+	c := NewMemory(MemoryOptions[string]{TTL: time.Millisecond, CleanupInterval: time.Millisecond})
+	defer c.Stop()
+
+	c.Set("a", "1")
+	deadline := time.Now().Add(time.Second)
+	for c.Stats().Entries > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expired entry was not cleaned up")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if stats := c.Stats(); stats.Expirations != 1 {
+		t.Errorf("expirations = %d, want 1", stats.Expirations)
+	}
+}
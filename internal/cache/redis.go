@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisOptions[V any] struct {
+	// URL is a redis:// or rediss:// connection url
+	URL string
+	// Prefix namespaces the keys so several caches can share one server
+	Prefix string
+	// TTL is the lifetime of entries stored with Set
+	TTL time.Duration
+	// Codec serializes the values
+	Codec Codec[V]
+	// Timeout bounds every redis command, defaults to 500ms
+	Timeout time.Duration
+}
+
+// Redis is a cache stored on a server speaking the redis protocol, letting
+// several masters share one cache. failures are counted and treated as misses
+type Redis[V any] struct {
+	client  *redis.Client
+	prefix  string
+	ttl     time.Duration
+	codec   Codec[V]
+	timeout time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+func NewRedis[V any](opts RedisOptions[V]) (*Redis[V], error) {
+	redisOpts, err := redis.ParseURL(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis url: %w", err)
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 500 * time.Millisecond
+	}
+
+	c := &Redis[V]{
+		client:  redis.NewClient(redisOpts),
+		prefix:  opts.Prefix,
+		ttl:     opts.TTL,
+		codec:   opts.Codec,
+		timeout: opts.Timeout,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		c.client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return c, nil
+}
+
+func (c *Redis[V]) Set(key string, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores a value that expires after ttl instead of the cache
+// default, a ttl of 0 or less falls back to the default
+func (c *Redis[V]) SetWithTTL(key string, value V, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		c.errors.Add(1)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.prefix+key, data, ttl).Err(); err != nil {
+		c.errors.Add(1)
+	}
+}
+
+func (c *Redis[V]) Get(key string) (V, bool) {
+	var zero V
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.errors.Add(1)
+		}
+		c.misses.Add(1)
+		return zero, false
+	}
+
+	value, err := c.codec.Unmarshal(data)
+	if err != nil {
+		c.errors.Add(1)
+		c.misses.Add(1)
+		return zero, false
+	}
+
+	c.hits.Add(1)
+	return value, true
+}
+
+func (c *Redis[V]) Delete(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.prefix+key).Err(); err != nil {
+		c.errors.Add(1)
+	}
+}
+
+// Stats reports the counters of this process only, entries and bytes live on
+// the server and are not tracked
+func (c *Redis[V]) Stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+// Stop closes the connection pool
+func (c *Redis[V]) Stop() {
+	c.client.Close()
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"google.golang.org/protobuf/proto"
+
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+)
+
+func newTestRedis(t *testing.T) (*Redis[*v1.AccountData], *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	c, err := NewRedis(RedisOptions[*v1.AccountData]{
+		URL:    "redis://" + server.Addr(),
+		Prefix: "accounts:",
+		TTL:    time.Hour,
+		Codec:  ProtoCodec(func() *v1.AccountData { return &v1.AccountData{} }),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Stop)
+	return c, server
+}
+
+func TestRedis(t *testing.T) {
+	c, server := newTestRedis(t)
+
+	account := &v1.AccountData{
+		Puuid:        "e83573ec-ec6f-5034-9a38-ed0ccf8dbb1b",
+		Region:       "eu",
+		AccountLevel: 123,
+		Name:         "Player",
+		Tag:          "EUW",
+		Card:         "card",
+		Title:        "title",
+		UpdatedAt:    "2026-01-02T03:04:05Z",
+	}
+
+	if _, ok := c.Get("player#euw"); ok {
+		t.Fatal("empty cache returned a value")
+	}
+
+	c.Set("player#euw", account)
+	if !server.Exists("accounts:player#euw") {
+		t.Fatalf("key not prefixed, have %v", server.Keys())
+	}
+	if ttl := server.TTL("accounts:player#euw"); ttl != time.Hour {
+		t.Errorf("ttl = %v, want the default %v", ttl, time.Hour)
+	}
+
+	got, ok := c.Get("player#euw")
+	if !ok {
+		t.Fatal("value not cached")
+	}
+	if !proto.Equal(got, account) {
+		t.Errorf("round trip changed the value: got %v, want %v", got, account)
+	}
+
+	c.SetWithTTL("short#euw", account, time.Minute)
+	if ttl := server.TTL("accounts:short#euw"); ttl != time.Minute {
+		t.Errorf("ttl = %v, want %v", ttl, time.Minute)
+	}
+	c.SetWithTTL("zero#euw", account, 0)
+	if ttl := server.TTL("accounts:zero#euw"); ttl != time.Hour {
+		t.Errorf("zero ttl = %v, want the default %v", ttl, time.Hour)
+	}
+
+	server.FastForward(2 * time.Minute)
+	if _, ok := c.Get("short#euw"); ok {
+		t.Error("expired value returned")
+	}
+
+	c.Delete("player#euw")
+	if _, ok := c.Get("player#euw"); ok {
+		t.Error("deleted value returned")
+	}
+
+	want := Stats{Hits: 1, Misses: 3}
+	if stats := c.Stats(); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestRedisFailuresAreMisses(t *testing.T) {
+	c, server := newTestRedis(t)
+
+	// the wire format can't be decoded
+	server.Set("accounts:garbage#euw", "\xff\xff\xff")
+	if _, ok := c.Get("garbage#euw"); ok {
+		t.Error("undecodable value returned")
+	}
+
+	server.Close()
+	c.Set("player#euw", &v1.AccountData{Puuid: "puuid"})
+	if _, ok := c.Get("player#euw"); ok {
+		t.Error("value returned with the server down")
+	}
+
+	want := Stats{Misses: 2, Errors: 3}
+	if stats := c.Stats(); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
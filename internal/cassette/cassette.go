@@ -0,0 +1,97 @@
+// Package cassette records the HTTP traffic between a node and riot, the LCU
+// and PD, to sanitized cassette files and replays them, so an edge case
+// captured once on a real riot client can be reproduced in tests anywhere.
+// the LCU event websocket isn't recorded, replayed resolves poll instead
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cassette is a recorded sequence of HTTP exchanges
+type Cassette struct {
+	RecordedAt   time.Time     `json:"recorded_at"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response riot gave. tokens and PUUIDs
+// are already scrubbed from both
+type Interaction struct {
+	Method   string   `json:"method"`
+	Host     string   `json:"host"`
+	URI      string   `json:"uri"`
+	Request  Body     `json:"request,omitempty"`
+	Response Response `json:"response"`
+}
+
+type Response struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   Body              `json:"body,omitempty"`
+}
+
+// Body is stored as JSON when it is a JSON object or array, so cassettes stay
+// readable and editable by hand, and as a string otherwise
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return trimmed, nil
+	}
+	return json.Marshal(string(b))
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+	// cassettes are saved indented, riot sends compact JSON
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return err
+	}
+	*b = compact.Bytes()
+	return nil
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, replacing it atomically
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
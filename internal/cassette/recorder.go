@@ -0,0 +1,128 @@
+package cassette
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// recordedHeaders are the response headers the clients look at, everything
+// else, cookies included, is left out of cassettes
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// Recorder captures the exchanges of the transports it wraps into one
+// cassette. request headers are never recorded, they carry the lockfile
+// password and the riot tokens
+type Recorder struct {
+	path      string
+	sanitizer *sanitizer
+	logger    *zap.SugaredLogger
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder records into a cassette that is saved to path after every
+// exchange, so nothing is lost when the node is killed. an empty path only
+// keeps the cassette in memory
+func NewRecorder(path string, logger *zap.SugaredLogger) *Recorder {
+	return &Recorder{
+		path:      path,
+		sanitizer: newSanitizer(),
+		logger:    logger,
+		cassette:  Cassette{RecordedAt: time.Now().UTC()},
+	}
+}
+
+// Transport records the exchanges sent through next
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{recorder: r, next: next}
+}
+
+// Cassette returns a copy of what was recorded so far
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.cassette
+	c.Interactions = append([]Interaction(nil), r.cassette.Interactions...)
+	return &c
+}
+
+func (r *Recorder) record(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte) {
+	// PUUIDs are learned from both sides before anything is scrubbed, a url
+	// can name a player the body introduces
+	uri := req.URL.RequestURI()
+	r.sanitizer.learn(string(respBody), puuidKeys)
+	r.sanitizer.learn(string(reqBody), puuidKeys)
+	r.sanitizer.learn(uri, puuidPaths)
+
+	interaction := Interaction{
+		Method:  req.Method,
+		Host:    req.URL.Host,
+		URI:     r.sanitizer.scrub(uri),
+		Request: Body(r.sanitizer.scrub(string(reqBody))),
+		Response: Response{
+			Status: resp.StatusCode,
+			Body:   Body(r.sanitizer.scrub(string(respBody))),
+		},
+	}
+	for _, name := range recordedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			if interaction.Response.Header == nil {
+				interaction.Response.Header = make(map[string]string)
+			}
+			interaction.Response.Header[name] = value
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if r.path == "" {
+		return
+	}
+	if err := r.cassette.Save(r.path); err != nil {
+		r.logger.Warnw("failed to save cassette", "path", r.path, "error", err)
+	}
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		// RoundTrip must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	t.recorder.record(req, reqBody, resp, respBody)
+	return resp, nil
+}
@@ -0,0 +1,78 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrNotRecorded is returned for a request the cassette has no answer for
+var ErrNotRecorded = errors.New("request not recorded in cassette")
+
+// Replayer is an http.RoundTripper answering from a cassette. requests are
+// matched by method and uri, the host is ignored since the LCU port changes
+// every run. a request recorded several times, e.g. a poll, gets its answers
+// in the recorded order and the last one once they run out
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	served       map[string]int
+}
+
+func NewReplayer(c *Cassette) *Replayer {
+	r := &Replayer{
+		interactions: make(map[string][]Interaction),
+		served:       make(map[string]int),
+	}
+	for _, interaction := range c.Interactions {
+		key := interaction.Method + " " + interaction.URI
+		r.interactions[key] = append(r.interactions[key], interaction)
+	}
+	return r
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := req.Method + " " + req.URL.RequestURI()
+
+	r.mu.Lock()
+	recorded := r.interactions[key]
+	if len(recorded) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNotRecorded, key)
+	}
+	i := min(r.served[key], len(recorded)-1)
+	r.served[key]++
+	r.mu.Unlock()
+
+	recordedResp := recorded[i].Response
+	header := make(http.Header)
+	for name, value := range recordedResp.Header {
+		header.Set(name, value)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recordedResp.Status, http.StatusText(recordedResp.Status)),
+		StatusCode:    recordedResp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(recordedResp.Body)),
+		ContentLength: int64(len(recordedResp.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Served counts how often a request was answered, e.g. "GET /chat/v4/friends"
+func (r *Replayer) Served(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.served[key]
+}
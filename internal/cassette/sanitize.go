@@ -0,0 +1,73 @@
+package cassette
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces every token in a cassette
+const Redacted = "redacted"
+
+var (
+	uuidPattern = `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
+
+	// keys riot puts PUUIDs under, chat pids are puuid@region.pvp.net
+	puuidKeys = regexp.MustCompile(`"(?:puuid|subject|Subject|sub|pid)"\s*:\s*"(` + uuidPattern + `)`)
+	// PD paths that take a PUUID
+	puuidPaths = regexp.MustCompile(`/(?:history|players)/(` + uuidPattern + `)`)
+
+	tokenKeys = regexp.MustCompile(`"(accessToken|token|idToken|access_token|entitlements_token)"(\s*:\s*)"[^"]*"`)
+	jwts      = regexp.MustCompile(`eyJ[\w-]+\.[\w-]+\.[\w-]*`)
+)
+
+// sanitizer scrubs tokens and swaps PUUIDs for placeholders. a PUUID gets the
+// same placeholder everywhere it shows up, so a replayed resolve still finds
+// the player it got from the friend request in the match details
+type sanitizer struct {
+	mu     sync.Mutex
+	puuids map[string]*placeholder
+}
+
+type placeholder struct {
+	pattern *regexp.Regexp
+	value   string
+}
+
+func newSanitizer() *sanitizer {
+	return &sanitizer{puuids: make(map[string]*placeholder)}
+}
+
+// learn remembers the PUUIDs in text so later exchanges that only mention
+// them, e.g. in a url, are scrubbed too
+func (s *sanitizer) learn(text string, patterns ...*regexp.Regexp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pattern := range patterns {
+		for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+			puuid := strings.ToLower(match[1])
+			if _, ok := s.puuids[puuid]; !ok {
+				s.puuids[puuid] = &placeholder{
+					pattern: regexp.MustCompile("(?i)" + puuid),
+					value:   fmt.Sprintf("00000000-0000-4000-8000-%012d", len(s.puuids)+1),
+				}
+			}
+		}
+	}
+}
+
+// scrub removes tokens and the PUUIDs learned so far from text
+func (s *sanitizer) scrub(text string) string {
+	text = tokenKeys.ReplaceAllString(text, `"$1"$2"`+Redacted+`"`)
+	text = jwts.ReplaceAllString(text, Redacted)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.puuids {
+		text = p.pattern.ReplaceAllLiteralString(text, p.value)
+	}
+	return text
+}
@@ -17,16 +17,60 @@ func init() {
 type MasterConfig struct {
 	TCPPort      int
 	APIPort      int
-	DatabasePath string
-	CacheTTL     time.Duration
+	DatabaseURL  string
+	CacheBackend string
+	RedisURL     string
+	RedisPrefix  string
+	CacheEntries int
+	CacheBytes   int64
+	FreshFor     time.Duration
+	StaleFor     time.Duration
+	NotFoundFor  time.Duration
+
+	CleanupInterval  time.Duration
+	OptimizeInterval time.Duration
+	VacuumInterval   time.Duration
+	AccountRetention time.Duration
+	LogRetention     time.Duration
+	ClientRetention  time.Duration
+
+	BackupInterval time.Duration
+	BackupDir      string
+	BackupKeep     int
+
+	// AdminAddress is where the admin api listens, empty disables it
+	AdminAddress string
+	// AdminToken, when set, is required as a bearer token by the admin api
+	AdminToken string
 }
 
 func LoadMasterConfig() *MasterConfig {
 	return &MasterConfig{
 		TCPPort:      getEnvInt("TCP_PORT", 8080),
 		APIPort:      getEnvInt("API_PORT", 8081),
-		DatabasePath: getEnv("DATABASE_PATH", "./data/valorant.db"),
-		CacheTTL:     time.Duration(getEnvInt("CACHE_TTL_MINUTES", 60)) * time.Minute,
+		DatabaseURL:  getEnv("DATABASE_URL", getEnv("DATABASE_PATH", "./data/valorant.db")),
+		CacheBackend: getEnv("CACHE_BACKEND", "memory"),
+		RedisURL:     getEnv("REDIS_URL", "redis://localhost:6379/0"),
+		RedisPrefix:  getEnv("REDIS_PREFIX", "valorant:"),
+		CacheEntries: getEnvInt("CACHE_MAX_ENTRIES", 100000),
+		CacheBytes:   int64(getEnvInt("CACHE_MAX_MB", 64)) * 1024 * 1024,
+		FreshFor:     time.Duration(getEnvInt("ACCOUNT_FRESH_MINUTES", 60)) * time.Minute,
+		StaleFor:     time.Duration(getEnvInt("ACCOUNT_STALE_MINUTES", 24*60)) * time.Minute,
+		NotFoundFor:  time.Duration(getEnvInt("NEGATIVE_CACHE_MINUTES", 10)) * time.Minute,
+
+		CleanupInterval:  time.Duration(getEnvInt("CLEANUP_INTERVAL_MINUTES", 60)) * time.Minute,
+		OptimizeInterval: time.Duration(getEnvInt("OPTIMIZE_INTERVAL_HOURS", 24)) * time.Hour,
+		VacuumInterval:   time.Duration(getEnvInt("VACUUM_INTERVAL_HOURS", 7*24)) * time.Hour,
+		AccountRetention: time.Duration(getEnvInt("ACCOUNT_RETENTION_DAYS", 7)) * 24 * time.Hour,
+		LogRetention:     time.Duration(getEnvInt("LOG_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		ClientRetention:  time.Duration(getEnvInt("CLIENT_RETENTION_DAYS", 30)) * 24 * time.Hour,
+
+		BackupInterval: time.Duration(getEnvInt("BACKUP_INTERVAL_HOURS", 24)) * time.Hour,
+		BackupDir:      getEnv("BACKUP_DIR", "./data/backups"),
+		BackupKeep:     getEnvInt("BACKUP_KEEP", 7),
+
+		AdminAddress: getEnv("ADMIN_ADDRESS", "127.0.0.1:8082"),
+		AdminToken:   getEnv("ADMIN_TOKEN", ""),
 	}
 }
 
@@ -34,6 +78,14 @@ type ClientConfig struct {
 	MasterAddress string `json:"master_address"`
 	ClientID      string `json:"client_id"`
 	LogLevel      string `json:"log_level"`
+	// LockfilePath overrides riot client lockfile discovery
+	LockfilePath string `json:"lockfile_path"`
+	// JournalPath is where the node records the friend requests it sent
+	JournalPath string `json:"journal_path"`
+	// PDURL overrides riot's player data url, {shard} is replaced per shard
+	PDURL string `json:"pd_url"`
+	// RecordPath, when set, records the node's riot traffic to a cassette
+	RecordPath string `json:"record_path"`
 }
 
 func LoadClientConfig() (*ClientConfig, error) {
@@ -52,6 +104,10 @@ func LoadClientConfig() (*ClientConfig, error) {
 		if cfg.LogLevel == "" {
 			cfg.LogLevel = "info"
 		}
+
+		if cfg.JournalPath == "" {
+			cfg.JournalPath = "friend-requests.json"
+		}
 		os.Setenv("LOG_LEVEL", cfg.LogLevel)
 
 		return &cfg, nil
@@ -61,6 +117,10 @@ func LoadClientConfig() (*ClientConfig, error) {
 		MasterAddress: getEnv("MASTER_ADDRESS", "localhost:8080"),
 		ClientID:      getEnv("CLIENT_ID", ""),
 		LogLevel:      getEnv("LOG_LEVEL", "info"),
+		LockfilePath:  getEnv("LOCKFILE_PATH", ""),
+		JournalPath:   getEnv("JOURNAL_PATH", "friend-requests.json"),
+		PDURL:         getEnv("PD_URL", ""),
+		RecordPath:    getEnv("RECORD_PATH", ""),
 	}
 
 	if cfg.ClientID == "" {
@@ -7,21 +7,23 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const cleanOldAccounts = `-- name: CleanOldAccounts :exec
 DELETE FROM accounts
-WHERE updated_at < datetime('now', '-7 days')
+WHERE updated_at < ?
 `
 
-func (q *Queries) CleanOldAccounts(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, cleanOldAccounts)
+func (q *Queries) CleanOldAccounts(ctx context.Context, updatedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, cleanOldAccounts, updatedAt)
 	return err
 }
 
 const getAccountByNameTag = `-- name: GetAccountByNameTag :one
 SELECT puuid, region, account_level, name, tag, card, title, updated_at, created_at FROM accounts
 WHERE name = ? AND tag = ?
+ORDER BY updated_at DESC
 LIMIT 1
 `
 
@@ -70,6 +72,87 @@ func (q *Queries) GetAccountByPUUID(ctx context.Context, puuid string) (Account,
 	return i, err
 }
 
+const importAccount = `-- name: ImportAccount :exec
+INSERT INTO accounts (puuid, region, account_level, name, tag, card, title, updated_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(puuid) DO UPDATE SET
+    region = excluded.region,
+    account_level = excluded.account_level,
+    name = excluded.name,
+    tag = excluded.tag,
+    card = excluded.card,
+    title = excluded.title,
+    updated_at = excluded.updated_at
+WHERE datetime(excluded.updated_at) > datetime(accounts.updated_at)
+`
+
+type ImportAccountParams struct {
+	Puuid        string    `json:"puuid"`
+	Region       string    `json:"region"`
+	AccountLevel int64     `json:"account_level"`
+	Name         string    `json:"name"`
+	Tag          string    `json:"tag"`
+	Card         string    `json:"card"`
+	Title        string    `json:"title"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// timestamps are compared through datetime(), the driver and CURRENT_TIMESTAMP
+// write them in different formats
+func (q *Queries) ImportAccount(ctx context.Context, arg ImportAccountParams) error {
+	_, err := q.db.ExecContext(ctx, importAccount,
+		arg.Puuid,
+		arg.Region,
+		arg.AccountLevel,
+		arg.Name,
+		arg.Tag,
+		arg.Card,
+		arg.Title,
+		arg.UpdatedAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const listAccounts = `-- name: ListAccounts :many
+SELECT puuid, region, account_level, name, tag, card, title, updated_at, created_at FROM accounts
+ORDER BY puuid
+`
+
+func (q *Queries) ListAccounts(ctx context.Context) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.Puuid,
+			&i.Region,
+			&i.AccountLevel,
+			&i.Name,
+			&i.Tag,
+			&i.Card,
+			&i.Title,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const upsertAccount = `-- name: UpsertAccount :exec
 INSERT INTO accounts (puuid, region, account_level, name, tag, card, title, updated_at)
 VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
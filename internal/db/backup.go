@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupPrefix = "valorant-"
+	backupSuffix = ".db"
+	// backupStepPages is how much is copied while holding the read lock,
+	// writers get a chance in between
+	backupStepPages = 1024
+)
+
+// Backup copies the live SQLite database to path with the SQLite online
+// backup API, without blocking writers for the whole copy
+func (d *Database) Backup(ctx context.Context, path string) error {
+	if d.dialect.name != SQLite {
+		return fmt.Errorf("backups are only supported for sqlite, use pg_dump for %s", d.dialect.name)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup file %s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		return backupSQLite(ctx, driverConn, path)
+	})
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	return nil
+}
+
+// BackupInto writes a timestamped backup into dir, then deletes all but the
+// newest keep backups there. keep <= 0 keeps everything
+func (d *Database) BackupInto(ctx context.Context, dir string, keep int) (string, error) {
+	path := filepath.Join(dir, backupPrefix+time.Now().UTC().Format("20060102-150405")+backupSuffix)
+	if err := d.Backup(ctx, path); err != nil {
+		return "", err
+	}
+
+	if keep <= 0 {
+		return path, nil
+	}
+	return path, pruneBackups(dir, keep)
+}
+
+// pruneBackups relies on the timestamped names sorting oldest first
+func pruneBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
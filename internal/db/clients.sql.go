@@ -7,20 +7,21 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const cleanStaleClients = `-- name: CleanStaleClients :exec
 DELETE FROM clients
-WHERE last_heartbeat < datetime('now', '-2 minutes')
+WHERE last_heartbeat < ?
 `
 
-func (q *Queries) CleanStaleClients(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, cleanStaleClients)
+func (q *Queries) CleanStaleClients(ctx context.Context, lastHeartbeat time.Time) error {
+	_, err := q.db.ExecContext(ctx, cleanStaleClients, lastHeartbeat)
 	return err
 }
 
 const getAllClients = `-- name: GetAllClients :many
-SELECT client_id, last_heartbeat, lcu_available, connected_at, version FROM clients
+SELECT client_id, last_heartbeat, lcu_available, connected_at, version, remote_address, game_version, requests_served, failures, total_latency_ms, last_error, status, status_detail, friend_request_backlog FROM clients
 ORDER BY last_heartbeat DESC
 `
 
@@ -39,6 +40,15 @@ func (q *Queries) GetAllClients(ctx context.Context) ([]Client, error) {
 			&i.LcuAvailable,
 			&i.ConnectedAt,
 			&i.Version,
+			&i.RemoteAddress,
+			&i.GameVersion,
+			&i.RequestsServed,
+			&i.Failures,
+			&i.TotalLatencyMs,
+			&i.LastError,
+			&i.Status,
+			&i.StatusDetail,
+			&i.FriendRequestBacklog,
 		); err != nil {
 			return nil, err
 		}
@@ -54,8 +64,8 @@ func (q *Queries) GetAllClients(ctx context.Context) ([]Client, error) {
 }
 
 const getAvailableClients = `-- name: GetAvailableClients :many
-SELECT client_id, last_heartbeat, lcu_available, connected_at, version FROM clients
-WHERE lcu_available = TRUE
+SELECT client_id, last_heartbeat, lcu_available, connected_at, version, remote_address, game_version, requests_served, failures, total_latency_ms, last_error, status, status_detail, friend_request_backlog FROM clients
+WHERE (status = 'ready' OR (status = '' AND lcu_available = TRUE))
   AND last_heartbeat > datetime('now', '-30 seconds')
 ORDER BY last_heartbeat DESC
 `
@@ -75,6 +85,15 @@ func (q *Queries) GetAvailableClients(ctx context.Context) ([]Client, error) {
 			&i.LcuAvailable,
 			&i.ConnectedAt,
 			&i.Version,
+			&i.RemoteAddress,
+			&i.GameVersion,
+			&i.RequestsServed,
+			&i.Failures,
+			&i.TotalLatencyMs,
+			&i.LastError,
+			&i.Status,
+			&i.StatusDetail,
+			&i.FriendRequestBacklog,
 		); err != nil {
 			return nil, err
 		}
@@ -89,24 +108,57 @@ func (q *Queries) GetAvailableClients(ctx context.Context) ([]Client, error) {
 	return items, nil
 }
 
+const recordClientRequest = `-- name: RecordClientRequest :exec
+UPDATE clients
+SET requests_served = requests_served + 1,
+    failures = failures + ?1,
+    total_latency_ms = total_latency_ms + ?2,
+    last_error = COALESCE(?3, last_error)
+WHERE client_id = ?4
+`
+
+type RecordClientRequestParams struct {
+	Failures  int64   `json:"failures"`
+	LatencyMs int64   `json:"latency_ms"`
+	LastError *string `json:"last_error"`
+	ClientID  string  `json:"client_id"`
+}
+
+func (q *Queries) RecordClientRequest(ctx context.Context, arg RecordClientRequestParams) error {
+	_, err := q.db.ExecContext(ctx, recordClientRequest,
+		arg.Failures,
+		arg.LatencyMs,
+		arg.LastError,
+		arg.ClientID,
+	)
+	return err
+}
+
 const registerClient = `-- name: RegisterClient :exec
-INSERT INTO clients (client_id, version, last_heartbeat, lcu_available, connected_at)
-VALUES (?, ?, CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP)
+INSERT INTO clients (client_id, version, remote_address, last_heartbeat, lcu_available, connected_at)
+VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP)
 ON CONFLICT(client_id) DO UPDATE SET
     version = excluded.version,
+    remote_address = excluded.remote_address,
     last_heartbeat = CURRENT_TIMESTAMP,
     lcu_available = excluded.lcu_available,
     connected_at = CURRENT_TIMESTAMP
 `
 
 type RegisterClientParams struct {
-	ClientID     string `json:"client_id"`
-	Version      string `json:"version"`
-	LcuAvailable bool   `json:"lcu_available"`
+	ClientID      string `json:"client_id"`
+	Version       string `json:"version"`
+	RemoteAddress string `json:"remote_address"`
+	LcuAvailable  bool   `json:"lcu_available"`
 }
 
 func (q *Queries) RegisterClient(ctx context.Context, arg RegisterClientParams) error {
-	_, err := q.db.ExecContext(ctx, registerClient, arg.ClientID, arg.Version, arg.LcuAvailable)
+	_, err := q.db.ExecContext(ctx, registerClient,
+		arg.ClientID,
+		arg.Version,
+		arg.RemoteAddress,
+		arg.LcuAvailable,
+	)
 	return err
 }
 
@@ -123,16 +175,31 @@ func (q *Queries) RemoveClient(ctx context.Context, clientID string) error {
 const updateClientHeartbeat = `-- name: UpdateClientHeartbeat :exec
 UPDATE clients
 SET last_heartbeat = CURRENT_TIMESTAMP,
-    lcu_available = ?
+    lcu_available = ?,
+    game_version = ?,
+    status = ?,
+    status_detail = ?,
+    friend_request_backlog = ?
 WHERE client_id = ?
 `
 
 type UpdateClientHeartbeatParams struct {
-	LcuAvailable bool   `json:"lcu_available"`
-	ClientID     string `json:"client_id"`
+	LcuAvailable         bool   `json:"lcu_available"`
+	GameVersion          string `json:"game_version"`
+	Status               string `json:"status"`
+	StatusDetail         string `json:"status_detail"`
+	FriendRequestBacklog int64  `json:"friend_request_backlog"`
+	ClientID             string `json:"client_id"`
 }
 
 func (q *Queries) UpdateClientHeartbeat(ctx context.Context, arg UpdateClientHeartbeatParams) error {
-	_, err := q.db.ExecContext(ctx, updateClientHeartbeat, arg.LcuAvailable, arg.ClientID)
+	_, err := q.db.ExecContext(ctx, updateClientHeartbeat,
+		arg.LcuAvailable,
+		arg.GameVersion,
+		arg.Status,
+		arg.StatusDetail,
+		arg.FriendRequestBacklog,
+		arg.ClientID,
+	)
 	return err
 }
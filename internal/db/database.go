@@ -1,22 +1,52 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
-// Database wraps the SQL database and SQLC queries
+// Database wraps the SQL database and the SQLC queries of its engine, SQLite
+// or PostgreSQL
 type Database struct {
-	*Queries
-	db *sql.DB
+	Querier
+	db      *sql.DB
+	dialect dialect
+}
+
+// New opens the database and applies pending migrations
+func New(databaseURL string) (*Database, error) {
+	database, err := Open(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := database.Migrate(context.Background()); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return database, nil
 }
 
-// New creates a new database connection
-func New(dbPath string) (*Database, error) {
+// MemoryPath opens a SQLite database that only lives as long as the process,
+// e.g. for tests
+const MemoryPath = ":memory:"
+
+// Open creates a new database connection without touching the schema.
+// postgres:// urls connect to PostgreSQL, anything else is a SQLite file or
+// MemoryPath
+func Open(databaseURL string) (*Database, error) {
+	dialect, dsn := parseURL(databaseURL)
+	if dialect.name == Postgres {
+		return openPostgres(dsn)
+	}
+	return openSQLite(dsn)
+}
+
+func openSQLite(dbPath string) (*Database, error) {
 	// Ensure directory exists
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -24,11 +54,17 @@ func New(dbPath string) (*Database, error) {
 	}
 
 	// Open database
-	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	db, err := sql.Open(sqliteDialect.driver, sqliteDSN(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// every connection to :memory: opens its own empty database, so an
+	// in-memory database has to live on a single connection
+	if dbPath == MemoryPath {
+		db.SetMaxOpenConns(1)
+	}
+
 	// Set pragmas for performance
 	pragmas := []string{
 		"PRAGMA synchronous = NORMAL",
@@ -43,31 +79,65 @@ func New(dbPath string) (*Database, error) {
 		}
 	}
 
-	// Run migrations
-	if err := runMigrations(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	return newDatabase(db, sqliteDialect), nil
+}
+
+func openPostgres(dsn string) (*Database, error) {
+	db, err := sql.Open(postgresDialect.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	return newDatabase(db, postgresDialect), nil
+}
+
+func newDatabase(db *sql.DB, dialect dialect) *Database {
 	return &Database{
-		Queries: &Queries{db: db},
+		Querier: dialect.queries(db),
 		db:      db,
-	}, nil
+		dialect: dialect,
+	}
 }
 
-// Close closes the database connection
-func (d *Database) Close() error {
-	return d.db.Close()
+// Dialect names the engine behind the database, SQLite or Postgres
+func (d *Database) Dialect() string {
+	return d.dialect.name
 }
 
-// runMigrations runs the SQL migrations
-func runMigrations(db *sql.DB) error {
-	schema, err := os.ReadFile("./sql/schema/001_initial.sql")
+// InTx runs fn inside a transaction, committing if it returns nil
+func (d *Database) InTx(ctx context.Context, fn func(q Querier) error) error {
+	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	if _, err := db.Exec(string(schema)); err != nil {
-		return fmt.Errorf("failed to execute migration: %w", err)
+	defer tx.Rollback()
+
+	if err := fn(d.dialect.queries(tx)); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+// Vacuum gives space from deleted rows back, to the OS for SQLite and for
+// reuse within the tables for PostgreSQL
+func (d *Database) Vacuum(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, d.dialect.vacuum)
+	return err
+}
+
+// Optimize lets the engine refresh the statistics its query planner uses
+func (d *Database) Optimize(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, d.dialect.optimize)
+	return err
+}
+
+// Close closes the database connection
+func (d *Database) Close() error {
+	return d.db.Close()
 }
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/ferrarinobrakes/unofficial-valorant-api/sql/schema"
+)
+
+const (
+	SQLite   = "sqlite"
+	Postgres = "postgres"
+)
+
+// migrationLockID keys the postgres advisory lock taken while migrating, so
+// masters starting together don't apply the same migration twice
+const migrationLockID = 7238461
+
+// dialect is everything that differs between the supported SQL engines
+type dialect struct {
+	name       string
+	driver     string
+	migrations func() (fs.FS, error)
+	queries    func(db DBTX) Querier
+	// recordMigration inserts (version, name) into schema_migrations
+	recordMigration string
+	vacuum          string
+	optimize        string
+	// lock and unlock serialize migrations across processes, empty when the
+	// engine already does
+	lock   string
+	unlock string
+}
+
+var sqliteDialect = dialect{
+	name:   SQLite,
+	driver: sqliteDriver,
+	migrations: func() (fs.FS, error) {
+		return schema.Migrations, nil
+	},
+	queries: func(db DBTX) Querier {
+		return &Queries{db: db}
+	},
+	recordMigration: "INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+	vacuum:          "VACUUM",
+	optimize:        "PRAGMA optimize",
+}
+
+var postgresDialect = dialect{
+	name:   Postgres,
+	driver: "pgx",
+	migrations: func() (fs.FS, error) {
+		return fs.Sub(schema.PostgresMigrations, "postgres")
+	},
+	queries:         newPgQueries,
+	recordMigration: "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+	vacuum:          "VACUUM",
+	optimize:        "ANALYZE",
+	lock:            fmt.Sprintf("SELECT pg_advisory_lock(%d)", migrationLockID),
+	unlock:          fmt.Sprintf("SELECT pg_advisory_unlock(%d)", migrationLockID),
+}
+
+// parseURL picks the engine for a DATABASE_URL. postgres:// and
+// postgresql:// urls go to PostgreSQL, sqlite:// urls and plain file paths
+// to SQLite
+func parseURL(databaseURL string) (dialect, string) {
+	switch {
+	case strings.HasPrefix(databaseURL, "postgres://"), strings.HasPrefix(databaseURL, "postgresql://"):
+		return postgresDialect, databaseURL
+	case strings.HasPrefix(databaseURL, "sqlite://"):
+		return sqliteDialect, strings.TrimPrefix(databaseURL, "sqlite://")
+	default:
+		return sqliteDialect, databaseURL
+	}
+}
+
+func (d dialect) withLock(ctx context.Context, conn DBTX, fn func() error) error {
+	if d.lock == "" {
+		return fn()
+	}
+
+	if _, err := conn.ExecContext(ctx, d.lock); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), d.unlock)
+
+	return fn()
+}
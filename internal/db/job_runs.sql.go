@@ -0,0 +1,55 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: job_runs.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const getJobRuns = `-- name: GetJobRuns :many
+SELECT name, last_run FROM job_runs
+`
+
+func (q *Queries) GetJobRuns(ctx context.Context) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, getJobRuns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []JobRun{}
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(&i.Name, &i.LastRun); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordJobRun = `-- name: RecordJobRun :exec
+INSERT INTO job_runs (name, last_run)
+VALUES (?, ?)
+ON CONFLICT(name) DO UPDATE SET
+    last_run = excluded.last_run
+`
+
+type RecordJobRunParams struct {
+	Name    string    `json:"name"`
+	LastRun time.Time `json:"last_run"`
+}
+
+func (q *Queries) RecordJobRun(ctx context.Context, arg RecordJobRunParams) error {
+	_, err := q.db.ExecContext(ctx, recordJobRun, arg.Name, arg.LastRun)
+	return err
+}
@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration is a schema file named NNN_description.sql, applied in version order
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus is a known migration and when it was applied, AppliedAt is
+// nil for pending migrations
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// LoadMigrations reads the migrations embedded in the binary for the engine
+// of the database
+func (d *Database) LoadMigrations() ([]Migration, error) {
+	fsys, err := d.dialect.migrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s migrations: %w", d.dialect.name, err)
+	}
+	return loadMigrations(fsys)
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migration files: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	seen := make(map[int]string)
+	for _, file := range files {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q, expected NNN_name.sql", file)
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("migrations %q and %q share version %d", other, file, version)
+		}
+		seen[version] = file
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file: %w", err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			SQL:     string(data),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrationStatus lists every known migration and whether it has been applied
+func (d *Database) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := d.LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := d.appliedMigrations(ctx, d.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if appliedAt, ok := applied[m.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Migrate applies all pending migrations, each in its own transaction, and
+// returns the ones it applied. masters sharing a PostgreSQL database take
+// turns so each migration runs once
+func (d *Database) Migrate(ctx context.Context) ([]Migration, error) {
+	migrations, err := d.LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// the lock belongs to a session, so everything runs on one connection
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var ran []Migration
+	err = d.dialect.withLock(ctx, conn, func() error {
+		applied, err := d.appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := d.applyMigration(ctx, conn, m); err != nil {
+				return err
+			}
+			ran = append(ran, m)
+		}
+		return nil
+	})
+
+	return ran, err
+}
+
+func (d *Database) applyMigration(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %03d_%s: %w", m.Version, m.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("failed to execute migration %03d_%s: %w", m.Version, m.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, d.dialect.recordMigration, m.Version, m.Name); err != nil {
+		return fmt.Errorf("failed to record migration %03d_%s: %w", m.Version, m.Name, err)
+	}
+
+	return tx.Commit()
+}
+
+func (d *Database) appliedMigrations(ctx context.Context, conn DBTX) (map[int]time.Time, error) {
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
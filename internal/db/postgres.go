@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db/postgres"
+)
+
+// pgQueries runs the queries generated for PostgreSQL behind the Querier
+// interface. both engines are generated from the same query names and column
+// types, so params and rows convert directly between the two packages
+type pgQueries struct {
+	q *postgres.Queries
+}
+
+var _ Querier = (*pgQueries)(nil)
+
+func newPgQueries(db DBTX) Querier {
+	return &pgQueries{q: postgres.New(db)}
+}
+
+func (p *pgQueries) CleanExpiredNegativeLookups(ctx context.Context, expiresAt time.Time) error {
+	return p.q.CleanExpiredNegativeLookups(ctx, expiresAt)
+}
+
+func (p *pgQueries) CleanOldAccounts(ctx context.Context, updatedAt time.Time) error {
+	return p.q.CleanOldAccounts(ctx, updatedAt)
+}
+
+func (p *pgQueries) CleanOldLogs(ctx context.Context, createdAt time.Time) error {
+	return p.q.CleanOldLogs(ctx, createdAt)
+}
+
+func (p *pgQueries) CleanStaleClients(ctx context.Context, lastHeartbeat time.Time) error {
+	return p.q.CleanStaleClients(ctx, lastHeartbeat)
+}
+
+func (p *pgQueries) DeleteNegativeLookup(ctx context.Context, arg DeleteNegativeLookupParams) error {
+	return p.q.DeleteNegativeLookup(ctx, postgres.DeleteNegativeLookupParams(arg))
+}
+
+func (p *pgQueries) GetAccountAliases(ctx context.Context, puuid string) ([]AccountAlias, error) {
+	rows, err := p.q.GetAccountAliases(ctx, puuid)
+	return convertRows(rows, func(row postgres.AccountAlias) AccountAlias { return AccountAlias(row) }), err
+}
+
+func (p *pgQueries) GetAccountByNameTag(ctx context.Context, arg GetAccountByNameTagParams) (Account, error) {
+	row, err := p.q.GetAccountByNameTag(ctx, postgres.GetAccountByNameTagParams(arg))
+	return Account(row), err
+}
+
+func (p *pgQueries) GetAccountByPUUID(ctx context.Context, puuid string) (Account, error) {
+	row, err := p.q.GetAccountByPUUID(ctx, puuid)
+	return Account(row), err
+}
+
+func (p *pgQueries) GetAccountSnapshots(ctx context.Context, arg GetAccountSnapshotsParams) ([]AccountSnapshot, error) {
+	rows, err := p.q.GetAccountSnapshots(ctx, postgres.GetAccountSnapshotsParams(arg))
+	return convertRows(rows, func(row postgres.AccountSnapshot) AccountSnapshot { return AccountSnapshot(row) }), err
+}
+
+func (p *pgQueries) GetAliasPUUIDByNameTag(ctx context.Context, arg GetAliasPUUIDByNameTagParams) (string, error) {
+	return p.q.GetAliasPUUIDByNameTag(ctx, postgres.GetAliasPUUIDByNameTagParams(arg))
+}
+
+func (p *pgQueries) GetAllClients(ctx context.Context) ([]Client, error) {
+	rows, err := p.q.GetAllClients(ctx)
+	return convertRows(rows, func(row postgres.Client) Client { return Client(row) }), err
+}
+
+func (p *pgQueries) GetAvailableClients(ctx context.Context) ([]Client, error) {
+	rows, err := p.q.GetAvailableClients(ctx)
+	return convertRows(rows, func(row postgres.Client) Client { return Client(row) }), err
+}
+
+func (p *pgQueries) GetJobRuns(ctx context.Context) ([]JobRun, error) {
+	rows, err := p.q.GetJobRuns(ctx)
+	return convertRows(rows, func(row postgres.JobRun) JobRun { return JobRun(row) }), err
+}
+
+func (p *pgQueries) GetLatestAccountSnapshot(ctx context.Context, puuid string) (AccountSnapshot, error) {
+	row, err := p.q.GetLatestAccountSnapshot(ctx, puuid)
+	return AccountSnapshot(row), err
+}
+
+func (p *pgQueries) GetNegativeLookup(ctx context.Context, arg GetNegativeLookupParams) (NegativeLookup, error) {
+	row, err := p.q.GetNegativeLookup(ctx, postgres.GetNegativeLookupParams(arg))
+	return NegativeLookup(row), err
+}
+
+func (p *pgQueries) GetRequestDurationAt(ctx context.Context, arg GetRequestDurationAtParams) (*int64, error) {
+	return p.q.GetRequestDurationAt(ctx, postgres.GetRequestDurationAtParams(arg))
+}
+
+func (p *pgQueries) ImportAccount(ctx context.Context, arg ImportAccountParams) error {
+	return p.q.ImportAccount(ctx, postgres.ImportAccountParams(arg))
+}
+
+func (p *pgQueries) ImportAccountAlias(ctx context.Context, arg ImportAccountAliasParams) error {
+	return p.q.ImportAccountAlias(ctx, postgres.ImportAccountAliasParams(arg))
+}
+
+func (p *pgQueries) ImportAccountSnapshot(ctx context.Context, arg ImportAccountSnapshotParams) error {
+	return p.q.ImportAccountSnapshot(ctx, postgres.ImportAccountSnapshotParams(arg))
+}
+
+func (p *pgQueries) InsertAccountSnapshot(ctx context.Context, arg InsertAccountSnapshotParams) error {
+	return p.q.InsertAccountSnapshot(ctx, postgres.InsertAccountSnapshotParams(arg))
+}
+
+func (p *pgQueries) ListAccounts(ctx context.Context) ([]Account, error) {
+	rows, err := p.q.ListAccounts(ctx)
+	return convertRows(rows, func(row postgres.Account) Account { return Account(row) }), err
+}
+
+func (p *pgQueries) ListAccountAliases(ctx context.Context) ([]AccountAlias, error) {
+	rows, err := p.q.ListAccountAliases(ctx)
+	return convertRows(rows, func(row postgres.AccountAlias) AccountAlias { return AccountAlias(row) }), err
+}
+
+func (p *pgQueries) ListAccountSnapshots(ctx context.Context) ([]AccountSnapshot, error) {
+	rows, err := p.q.ListAccountSnapshots(ctx)
+	return convertRows(rows, func(row postgres.AccountSnapshot) AccountSnapshot { return AccountSnapshot(row) }), err
+}
+
+func (p *pgQueries) LogRequest(ctx context.Context, arg LogRequestParams) error {
+	return p.q.LogRequest(ctx, postgres.LogRequestParams(arg))
+}
+
+func (p *pgQueries) RecordClientRequest(ctx context.Context, arg RecordClientRequestParams) error {
+	return p.q.RecordClientRequest(ctx, postgres.RecordClientRequestParams(arg))
+}
+
+func (p *pgQueries) RecordJobRun(ctx context.Context, arg RecordJobRunParams) error {
+	return p.q.RecordJobRun(ctx, postgres.RecordJobRunParams(arg))
+}
+
+func (p *pgQueries) RegisterClient(ctx context.Context, arg RegisterClientParams) error {
+	return p.q.RegisterClient(ctx, postgres.RegisterClientParams(arg))
+}
+
+func (p *pgQueries) RemoveClient(ctx context.Context, clientID string) error {
+	return p.q.RemoveClient(ctx, clientID)
+}
+
+func (p *pgQueries) UpdateClientHeartbeat(ctx context.Context, arg UpdateClientHeartbeatParams) error {
+	return p.q.UpdateClientHeartbeat(ctx, postgres.UpdateClientHeartbeatParams(arg))
+}
+
+func (p *pgQueries) UpsertAccount(ctx context.Context, arg UpsertAccountParams) error {
+	return p.q.UpsertAccount(ctx, postgres.UpsertAccountParams(arg))
+}
+
+func (p *pgQueries) UpsertAccountAlias(ctx context.Context, arg UpsertAccountAliasParams) error {
+	return p.q.UpsertAccountAlias(ctx, postgres.UpsertAccountAliasParams(arg))
+}
+
+func (p *pgQueries) UpsertNegativeLookup(ctx context.Context, arg UpsertNegativeLookupParams) error {
+	return p.q.UpsertNegativeLookup(ctx, postgres.UpsertNegativeLookupParams(arg))
+}
+
+func (p *pgQueries) GetRequestStats(ctx context.Context, createdAt time.Time) (GetRequestStatsRow, error) {
+	row, err := p.q.GetRequestStats(ctx, createdAt)
+	return GetRequestStatsRow{
+		TotalRequests:      row.TotalRequests,
+		SuccessfulRequests: &row.SuccessfulRequests,
+		AvgDurationMs:      &row.AvgDurationMs,
+	}, err
+}
+
+func (p *pgQueries) GetRequestStatsByClient(ctx context.Context, createdAt time.Time) ([]GetRequestStatsByClientRow, error) {
+	rows, err := p.q.GetRequestStatsByClient(ctx, createdAt)
+	return convertRows(rows, func(row postgres.GetRequestStatsByClientRow) GetRequestStatsByClientRow {
+		return GetRequestStatsByClientRow{
+			ClientID:           row.ClientID,
+			TotalRequests:      row.TotalRequests,
+			SuccessfulRequests: &row.SuccessfulRequests,
+			AvgDurationMs:      &row.AvgDurationMs,
+		}
+	}), err
+}
+
+func (p *pgQueries) GetRequestStatsBySource(ctx context.Context, createdAt time.Time) ([]GetRequestStatsBySourceRow, error) {
+	rows, err := p.q.GetRequestStatsBySource(ctx, createdAt)
+	return convertRows(rows, func(row postgres.GetRequestStatsBySourceRow) GetRequestStatsBySourceRow {
+		return GetRequestStatsBySourceRow{
+			Source:             row.Source,
+			TotalRequests:      row.TotalRequests,
+			SuccessfulRequests: &row.SuccessfulRequests,
+			AvgDurationMs:      &row.AvgDurationMs,
+		}
+	}), err
+}
+
+func convertRows[From, To any](rows []From, convert func(From) To) []To {
+	converted := make([]To, 0, len(rows))
+	for _, row := range rows {
+		converted = append(converted, convert(row))
+	}
+	return converted
+}
@@ -0,0 +1,144 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: account_aliases.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+const getAccountAliases = `-- name: GetAccountAliases :many
+SELECT puuid, name, tag, first_seen, last_seen FROM account_aliases
+WHERE puuid = $1
+ORDER BY last_seen DESC
+`
+
+func (q *Queries) GetAccountAliases(ctx context.Context, puuid string) ([]AccountAlias, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountAliases, puuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountAlias{}
+	for rows.Next() {
+		var i AccountAlias
+		if err := rows.Scan(
+			&i.Puuid,
+			&i.Name,
+			&i.Tag,
+			&i.FirstSeen,
+			&i.LastSeen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAliasPUUIDByNameTag = `-- name: GetAliasPUUIDByNameTag :one
+SELECT puuid FROM account_aliases
+WHERE lower(name) = lower($1) AND lower(tag) = lower($2)
+ORDER BY last_seen DESC
+LIMIT 1
+`
+
+type GetAliasPUUIDByNameTagParams struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+}
+
+func (q *Queries) GetAliasPUUIDByNameTag(ctx context.Context, arg GetAliasPUUIDByNameTagParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getAliasPUUIDByNameTag, arg.Name, arg.Tag)
+	var puuid string
+	err := row.Scan(&puuid)
+	return puuid, err
+}
+
+const importAccountAlias = `-- name: ImportAccountAlias :exec
+INSERT INTO account_aliases (puuid, name, tag, first_seen, last_seen)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (puuid, lower(name), lower(tag)) DO UPDATE SET
+    first_seen = LEAST(account_aliases.first_seen, excluded.first_seen),
+    last_seen = GREATEST(account_aliases.last_seen, excluded.last_seen)
+`
+
+type ImportAccountAliasParams struct {
+	Puuid     string    `json:"puuid"`
+	Name      string    `json:"name"`
+	Tag       string    `json:"tag"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+func (q *Queries) ImportAccountAlias(ctx context.Context, arg ImportAccountAliasParams) error {
+	_, err := q.db.ExecContext(ctx, importAccountAlias,
+		arg.Puuid,
+		arg.Name,
+		arg.Tag,
+		arg.FirstSeen,
+		arg.LastSeen,
+	)
+	return err
+}
+
+const listAccountAliases = `-- name: ListAccountAliases :many
+SELECT puuid, name, tag, first_seen, last_seen FROM account_aliases
+ORDER BY puuid, first_seen
+`
+
+func (q *Queries) ListAccountAliases(ctx context.Context) ([]AccountAlias, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountAliases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountAlias{}
+	for rows.Next() {
+		var i AccountAlias
+		if err := rows.Scan(
+			&i.Puuid,
+			&i.Name,
+			&i.Tag,
+			&i.FirstSeen,
+			&i.LastSeen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertAccountAlias = `-- name: UpsertAccountAlias :exec
+INSERT INTO account_aliases (puuid, name, tag, first_seen, last_seen)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT (puuid, lower(name), lower(tag)) DO UPDATE SET
+    last_seen = CURRENT_TIMESTAMP
+`
+
+type UpsertAccountAliasParams struct {
+	Puuid string `json:"puuid"`
+	Name  string `json:"name"`
+	Tag   string `json:"tag"`
+}
+
+func (q *Queries) UpsertAccountAlias(ctx context.Context, arg UpsertAccountAliasParams) error {
+	_, err := q.db.ExecContext(ctx, upsertAccountAlias, arg.Puuid, arg.Name, arg.Tag)
+	return err
+}
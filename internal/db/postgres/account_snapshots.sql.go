@@ -0,0 +1,162 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: account_snapshots.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+const getAccountSnapshots = `-- name: GetAccountSnapshots :many
+SELECT id, puuid, account_level, card, title, created_at FROM account_snapshots
+WHERE puuid = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2::bigint
+`
+
+type GetAccountSnapshotsParams struct {
+	Puuid string `json:"puuid"`
+	Limit int64  `json:"limit"`
+}
+
+func (q *Queries) GetAccountSnapshots(ctx context.Context, arg GetAccountSnapshotsParams) ([]AccountSnapshot, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountSnapshots, arg.Puuid, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountSnapshot{}
+	for rows.Next() {
+		var i AccountSnapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.Puuid,
+			&i.AccountLevel,
+			&i.Card,
+			&i.Title,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestAccountSnapshot = `-- name: GetLatestAccountSnapshot :one
+SELECT id, puuid, account_level, card, title, created_at FROM account_snapshots
+WHERE puuid = $1
+ORDER BY created_at DESC, id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestAccountSnapshot(ctx context.Context, puuid string) (AccountSnapshot, error) {
+	row := q.db.QueryRowContext(ctx, getLatestAccountSnapshot, puuid)
+	var i AccountSnapshot
+	err := row.Scan(
+		&i.ID,
+		&i.Puuid,
+		&i.AccountLevel,
+		&i.Card,
+		&i.Title,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const importAccountSnapshot = `-- name: ImportAccountSnapshot :exec
+INSERT INTO account_snapshots (puuid, account_level, card, title, created_at)
+SELECT $1::text, $2::bigint, $3::text, $4::text, $5::timestamptz
+WHERE NOT EXISTS (
+    SELECT 1 FROM account_snapshots
+    WHERE puuid = $1 AND account_level = $2 AND card = $3 AND title = $4
+        AND date_trunc('second', created_at) = date_trunc('second', $5)
+)
+`
+
+type ImportAccountSnapshotParams struct {
+	Puuid        string    `json:"puuid"`
+	AccountLevel int64     `json:"account_level"`
+	Card         string    `json:"card"`
+	Title        string    `json:"title"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// exports only keep whole seconds. the casts type the parameters, a select
+// list doesn't
+func (q *Queries) ImportAccountSnapshot(ctx context.Context, arg ImportAccountSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, importAccountSnapshot,
+		arg.Puuid,
+		arg.AccountLevel,
+		arg.Card,
+		arg.Title,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const insertAccountSnapshot = `-- name: InsertAccountSnapshot :exec
+INSERT INTO account_snapshots (puuid, account_level, card, title, created_at)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+`
+
+type InsertAccountSnapshotParams struct {
+	Puuid        string `json:"puuid"`
+	AccountLevel int64  `json:"account_level"`
+	Card         string `json:"card"`
+	Title        string `json:"title"`
+}
+
+func (q *Queries) InsertAccountSnapshot(ctx context.Context, arg InsertAccountSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, insertAccountSnapshot,
+		arg.Puuid,
+		arg.AccountLevel,
+		arg.Card,
+		arg.Title,
+	)
+	return err
+}
+
+const listAccountSnapshots = `-- name: ListAccountSnapshots :many
+SELECT id, puuid, account_level, card, title, created_at FROM account_snapshots
+ORDER BY id
+`
+
+func (q *Queries) ListAccountSnapshots(ctx context.Context) ([]AccountSnapshot, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountSnapshots)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountSnapshot{}
+	for rows.Next() {
+		var i AccountSnapshot
+		if err := rows.Scan(
+			&i.ID,
+			&i.Puuid,
+			&i.AccountLevel,
+			&i.Card,
+			&i.Title,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
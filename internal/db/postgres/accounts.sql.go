@@ -0,0 +1,188 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: accounts.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+const cleanOldAccounts = `-- name: CleanOldAccounts :exec
+DELETE FROM accounts
+WHERE updated_at < $1
+`
+
+func (q *Queries) CleanOldAccounts(ctx context.Context, updatedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, cleanOldAccounts, updatedAt)
+	return err
+}
+
+const getAccountByNameTag = `-- name: GetAccountByNameTag :one
+SELECT puuid, region, account_level, name, tag, card, title, updated_at, created_at FROM accounts
+WHERE lower(name) = lower($1) AND lower(tag) = lower($2)
+ORDER BY updated_at DESC
+LIMIT 1
+`
+
+type GetAccountByNameTagParams struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+}
+
+func (q *Queries) GetAccountByNameTag(ctx context.Context, arg GetAccountByNameTagParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByNameTag, arg.Name, arg.Tag)
+	var i Account
+	err := row.Scan(
+		&i.Puuid,
+		&i.Region,
+		&i.AccountLevel,
+		&i.Name,
+		&i.Tag,
+		&i.Card,
+		&i.Title,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAccountByPUUID = `-- name: GetAccountByPUUID :one
+SELECT puuid, region, account_level, name, tag, card, title, updated_at, created_at FROM accounts
+WHERE puuid = $1
+LIMIT 1
+`
+
+func (q *Queries) GetAccountByPUUID(ctx context.Context, puuid string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByPUUID, puuid)
+	var i Account
+	err := row.Scan(
+		&i.Puuid,
+		&i.Region,
+		&i.AccountLevel,
+		&i.Name,
+		&i.Tag,
+		&i.Card,
+		&i.Title,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const importAccount = `-- name: ImportAccount :exec
+INSERT INTO accounts (puuid, region, account_level, name, tag, card, title, updated_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT(puuid) DO UPDATE SET
+    region = excluded.region,
+    account_level = excluded.account_level,
+    name = excluded.name,
+    tag = excluded.tag,
+    card = excluded.card,
+    title = excluded.title,
+    updated_at = excluded.updated_at
+WHERE excluded.updated_at > accounts.updated_at
+`
+
+type ImportAccountParams struct {
+	Puuid        string    `json:"puuid"`
+	Region       string    `json:"region"`
+	AccountLevel int64     `json:"account_level"`
+	Name         string    `json:"name"`
+	Tag          string    `json:"tag"`
+	Card         string    `json:"card"`
+	Title        string    `json:"title"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (q *Queries) ImportAccount(ctx context.Context, arg ImportAccountParams) error {
+	_, err := q.db.ExecContext(ctx, importAccount,
+		arg.Puuid,
+		arg.Region,
+		arg.AccountLevel,
+		arg.Name,
+		arg.Tag,
+		arg.Card,
+		arg.Title,
+		arg.UpdatedAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const listAccounts = `-- name: ListAccounts :many
+SELECT puuid, region, account_level, name, tag, card, title, updated_at, created_at FROM accounts
+ORDER BY puuid
+`
+
+func (q *Queries) ListAccounts(ctx context.Context) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.Puuid,
+			&i.Region,
+			&i.AccountLevel,
+			&i.Name,
+			&i.Tag,
+			&i.Card,
+			&i.Title,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertAccount = `-- name: UpsertAccount :exec
+INSERT INTO accounts (puuid, region, account_level, name, tag, card, title, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+ON CONFLICT(puuid) DO UPDATE SET
+    region = excluded.region,
+    account_level = excluded.account_level,
+    name = excluded.name,
+    tag = excluded.tag,
+    card = excluded.card,
+    title = excluded.title,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertAccountParams struct {
+	Puuid        string `json:"puuid"`
+	Region       string `json:"region"`
+	AccountLevel int64  `json:"account_level"`
+	Name         string `json:"name"`
+	Tag          string `json:"tag"`
+	Card         string `json:"card"`
+	Title        string `json:"title"`
+}
+
+func (q *Queries) UpsertAccount(ctx context.Context, arg UpsertAccountParams) error {
+	_, err := q.db.ExecContext(ctx, upsertAccount,
+		arg.Puuid,
+		arg.Region,
+		arg.AccountLevel,
+		arg.Name,
+		arg.Tag,
+		arg.Card,
+		arg.Title,
+	)
+	return err
+}
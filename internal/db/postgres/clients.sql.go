@@ -0,0 +1,205 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: clients.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+const cleanStaleClients = `-- name: CleanStaleClients :exec
+DELETE FROM clients
+WHERE last_heartbeat < $1
+`
+
+func (q *Queries) CleanStaleClients(ctx context.Context, lastHeartbeat time.Time) error {
+	_, err := q.db.ExecContext(ctx, cleanStaleClients, lastHeartbeat)
+	return err
+}
+
+const getAllClients = `-- name: GetAllClients :many
+SELECT client_id, last_heartbeat, lcu_available, connected_at, version, remote_address, game_version, requests_served, failures, total_latency_ms, last_error, status, status_detail, friend_request_backlog FROM clients
+ORDER BY last_heartbeat DESC
+`
+
+func (q *Queries) GetAllClients(ctx context.Context) ([]Client, error) {
+	rows, err := q.db.QueryContext(ctx, getAllClients)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Client{}
+	for rows.Next() {
+		var i Client
+		if err := rows.Scan(
+			&i.ClientID,
+			&i.LastHeartbeat,
+			&i.LcuAvailable,
+			&i.ConnectedAt,
+			&i.Version,
+			&i.RemoteAddress,
+			&i.GameVersion,
+			&i.RequestsServed,
+			&i.Failures,
+			&i.TotalLatencyMs,
+			&i.LastError,
+			&i.Status,
+			&i.StatusDetail,
+			&i.FriendRequestBacklog,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAvailableClients = `-- name: GetAvailableClients :many
+SELECT client_id, last_heartbeat, lcu_available, connected_at, version, remote_address, game_version, requests_served, failures, total_latency_ms, last_error, status, status_detail, friend_request_backlog FROM clients
+WHERE (status = 'ready' OR (status = '' AND lcu_available = TRUE))
+  AND last_heartbeat > CURRENT_TIMESTAMP - INTERVAL '30 seconds'
+ORDER BY last_heartbeat DESC
+`
+
+func (q *Queries) GetAvailableClients(ctx context.Context) ([]Client, error) {
+	rows, err := q.db.QueryContext(ctx, getAvailableClients)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Client{}
+	for rows.Next() {
+		var i Client
+		if err := rows.Scan(
+			&i.ClientID,
+			&i.LastHeartbeat,
+			&i.LcuAvailable,
+			&i.ConnectedAt,
+			&i.Version,
+			&i.RemoteAddress,
+			&i.GameVersion,
+			&i.RequestsServed,
+			&i.Failures,
+			&i.TotalLatencyMs,
+			&i.LastError,
+			&i.Status,
+			&i.StatusDetail,
+			&i.FriendRequestBacklog,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordClientRequest = `-- name: RecordClientRequest :exec
+UPDATE clients
+SET requests_served = requests_served + 1,
+    failures = failures + $1,
+    total_latency_ms = total_latency_ms + $2,
+    last_error = COALESCE($3, last_error)
+WHERE client_id = $4
+`
+
+type RecordClientRequestParams struct {
+	Failures  int64   `json:"failures"`
+	LatencyMs int64   `json:"latency_ms"`
+	LastError *string `json:"last_error"`
+	ClientID  string  `json:"client_id"`
+}
+
+func (q *Queries) RecordClientRequest(ctx context.Context, arg RecordClientRequestParams) error {
+	_, err := q.db.ExecContext(ctx, recordClientRequest,
+		arg.Failures,
+		arg.LatencyMs,
+		arg.LastError,
+		arg.ClientID,
+	)
+	return err
+}
+
+const registerClient = `-- name: RegisterClient :exec
+INSERT INTO clients (client_id, version, remote_address, last_heartbeat, lcu_available, connected_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP, $4, CURRENT_TIMESTAMP)
+ON CONFLICT(client_id) DO UPDATE SET
+    version = excluded.version,
+    remote_address = excluded.remote_address,
+    last_heartbeat = CURRENT_TIMESTAMP,
+    lcu_available = excluded.lcu_available,
+    connected_at = CURRENT_TIMESTAMP
+`
+
+type RegisterClientParams struct {
+	ClientID      string `json:"client_id"`
+	Version       string `json:"version"`
+	RemoteAddress string `json:"remote_address"`
+	LcuAvailable  bool   `json:"lcu_available"`
+}
+
+func (q *Queries) RegisterClient(ctx context.Context, arg RegisterClientParams) error {
+	_, err := q.db.ExecContext(ctx, registerClient,
+		arg.ClientID,
+		arg.Version,
+		arg.RemoteAddress,
+		arg.LcuAvailable,
+	)
+	return err
+}
+
+const removeClient = `-- name: RemoveClient :exec
+DELETE FROM clients
+WHERE client_id = $1
+`
+
+func (q *Queries) RemoveClient(ctx context.Context, clientID string) error {
+	_, err := q.db.ExecContext(ctx, removeClient, clientID)
+	return err
+}
+
+const updateClientHeartbeat = `-- name: UpdateClientHeartbeat :exec
+UPDATE clients
+SET last_heartbeat = CURRENT_TIMESTAMP,
+    lcu_available = $1,
+    game_version = $2,
+    status = $3,
+    status_detail = $4,
+    friend_request_backlog = $5
+WHERE client_id = $6
+`
+
+type UpdateClientHeartbeatParams struct {
+	LcuAvailable         bool   `json:"lcu_available"`
+	GameVersion          string `json:"game_version"`
+	Status               string `json:"status"`
+	StatusDetail         string `json:"status_detail"`
+	FriendRequestBacklog int64  `json:"friend_request_backlog"`
+	ClientID             string `json:"client_id"`
+}
+
+func (q *Queries) UpdateClientHeartbeat(ctx context.Context, arg UpdateClientHeartbeatParams) error {
+	_, err := q.db.ExecContext(ctx, updateClientHeartbeat,
+		arg.LcuAvailable,
+		arg.GameVersion,
+		arg.Status,
+		arg.StatusDetail,
+		arg.FriendRequestBacklog,
+		arg.ClientID,
+	)
+	return err
+}
@@ -0,0 +1,85 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package postgres
+
+import (
+	"time"
+)
+
+type Account struct {
+	Puuid        string    `json:"puuid"`
+	Region       string    `json:"region"`
+	AccountLevel int64     `json:"account_level"`
+	Name         string    `json:"name"`
+	Tag          string    `json:"tag"`
+	Card         string    `json:"card"`
+	Title        string    `json:"title"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type AccountAlias struct {
+	Puuid     string    `json:"puuid"`
+	Name      string    `json:"name"`
+	Tag       string    `json:"tag"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type AccountSnapshot struct {
+	ID           int64     `json:"id"`
+	Puuid        string    `json:"puuid"`
+	AccountLevel int64     `json:"account_level"`
+	Card         string    `json:"card"`
+	Title        string    `json:"title"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Client struct {
+	ClientID             string    `json:"client_id"`
+	LastHeartbeat        time.Time `json:"last_heartbeat"`
+	LcuAvailable         bool      `json:"lcu_available"`
+	ConnectedAt          time.Time `json:"connected_at"`
+	Version              string    `json:"version"`
+	RemoteAddress        string    `json:"remote_address"`
+	GameVersion          string    `json:"game_version"`
+	RequestsServed       int64     `json:"requests_served"`
+	Failures             int64     `json:"failures"`
+	TotalLatencyMs       int64     `json:"total_latency_ms"`
+	LastError            *string   `json:"last_error"`
+	Status               string    `json:"status"`
+	StatusDetail         string    `json:"status_detail"`
+	FriendRequestBacklog int64     `json:"friend_request_backlog"`
+}
+
+type JobRun struct {
+	Name    string    `json:"name"`
+	LastRun time.Time `json:"last_run"`
+}
+
+type NegativeLookup struct {
+	Name         string    `json:"name"`
+	Tag          string    `json:"tag"`
+	ErrorCode    string    `json:"error_code"`
+	ErrorMessage string    `json:"error_message"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RequestLog struct {
+	ID           int64     `json:"id"`
+	RequestID    string    `json:"request_id"`
+	ClientID     *string   `json:"client_id"`
+	GameName     string    `json:"game_name"`
+	GameTag      string    `json:"game_tag"`
+	Puuid        *string   `json:"puuid"`
+	Success      bool      `json:"success"`
+	ErrorMessage *string   `json:"error_message"`
+	DurationMs   *int64    `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+	Method       string    `json:"method"`
+	Source       *string   `json:"source"`
+	ErrorCode    *string   `json:"error_code"`
+}
@@ -0,0 +1,90 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: negative_lookups.sql
+
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+const cleanExpiredNegativeLookups = `-- name: CleanExpiredNegativeLookups :exec
+DELETE FROM negative_lookups
+WHERE expires_at < $1
+`
+
+func (q *Queries) CleanExpiredNegativeLookups(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, cleanExpiredNegativeLookups, expiresAt)
+	return err
+}
+
+const deleteNegativeLookup = `-- name: DeleteNegativeLookup :exec
+DELETE FROM negative_lookups
+WHERE lower(name) = lower($1) AND lower(tag) = lower($2)
+`
+
+type DeleteNegativeLookupParams struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+}
+
+func (q *Queries) DeleteNegativeLookup(ctx context.Context, arg DeleteNegativeLookupParams) error {
+	_, err := q.db.ExecContext(ctx, deleteNegativeLookup, arg.Name, arg.Tag)
+	return err
+}
+
+const getNegativeLookup = `-- name: GetNegativeLookup :one
+SELECT name, tag, error_code, error_message, expires_at, created_at FROM negative_lookups
+WHERE lower(name) = lower($1) AND lower(tag) = lower($2)
+LIMIT 1
+`
+
+type GetNegativeLookupParams struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+}
+
+func (q *Queries) GetNegativeLookup(ctx context.Context, arg GetNegativeLookupParams) (NegativeLookup, error) {
+	row := q.db.QueryRowContext(ctx, getNegativeLookup, arg.Name, arg.Tag)
+	var i NegativeLookup
+	err := row.Scan(
+		&i.Name,
+		&i.Tag,
+		&i.ErrorCode,
+		&i.ErrorMessage,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertNegativeLookup = `-- name: UpsertNegativeLookup :exec
+INSERT INTO negative_lookups (name, tag, error_code, error_message, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+ON CONFLICT (lower(name), lower(tag)) DO UPDATE SET
+    error_code = excluded.error_code,
+    error_message = excluded.error_message,
+    expires_at = excluded.expires_at,
+    created_at = CURRENT_TIMESTAMP
+`
+
+type UpsertNegativeLookupParams struct {
+	Name         string    `json:"name"`
+	Tag          string    `json:"tag"`
+	ErrorCode    string    `json:"error_code"`
+	ErrorMessage string    `json:"error_message"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) UpsertNegativeLookup(ctx context.Context, arg UpsertNegativeLookupParams) error {
+	_, err := q.db.ExecContext(ctx, upsertNegativeLookup,
+		arg.Name,
+		arg.Tag,
+		arg.ErrorCode,
+		arg.ErrorMessage,
+		arg.ExpiresAt,
+	)
+	return err
+}
@@ -0,0 +1,53 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+type Querier interface {
+	CleanExpiredNegativeLookups(ctx context.Context, expiresAt time.Time) error
+	CleanOldAccounts(ctx context.Context, updatedAt time.Time) error
+	CleanOldLogs(ctx context.Context, createdAt time.Time) error
+	CleanStaleClients(ctx context.Context, lastHeartbeat time.Time) error
+	DeleteNegativeLookup(ctx context.Context, arg DeleteNegativeLookupParams) error
+	GetAccountAliases(ctx context.Context, puuid string) ([]AccountAlias, error)
+	GetAccountByNameTag(ctx context.Context, arg GetAccountByNameTagParams) (Account, error)
+	GetAccountByPUUID(ctx context.Context, puuid string) (Account, error)
+	GetAccountSnapshots(ctx context.Context, arg GetAccountSnapshotsParams) ([]AccountSnapshot, error)
+	GetAliasPUUIDByNameTag(ctx context.Context, arg GetAliasPUUIDByNameTagParams) (string, error)
+	GetAllClients(ctx context.Context) ([]Client, error)
+	GetAvailableClients(ctx context.Context) ([]Client, error)
+	GetJobRuns(ctx context.Context) ([]JobRun, error)
+	GetLatestAccountSnapshot(ctx context.Context, puuid string) (AccountSnapshot, error)
+	GetNegativeLookup(ctx context.Context, arg GetNegativeLookupParams) (NegativeLookup, error)
+	GetRequestDurationAt(ctx context.Context, arg GetRequestDurationAtParams) (*int64, error)
+	// admin calls are audited too but aren't api traffic
+	GetRequestStats(ctx context.Context, createdAt time.Time) (GetRequestStatsRow, error)
+	GetRequestStatsByClient(ctx context.Context, createdAt time.Time) ([]GetRequestStatsByClientRow, error)
+	GetRequestStatsBySource(ctx context.Context, createdAt time.Time) ([]GetRequestStatsBySourceRow, error)
+	ImportAccount(ctx context.Context, arg ImportAccountParams) error
+	ImportAccountAlias(ctx context.Context, arg ImportAccountAliasParams) error
+	// exports only keep whole seconds. the casts type the parameters, a select
+	// list doesn't
+	ImportAccountSnapshot(ctx context.Context, arg ImportAccountSnapshotParams) error
+	InsertAccountSnapshot(ctx context.Context, arg InsertAccountSnapshotParams) error
+	ListAccountAliases(ctx context.Context) ([]AccountAlias, error)
+	ListAccountSnapshots(ctx context.Context) ([]AccountSnapshot, error)
+	ListAccounts(ctx context.Context) ([]Account, error)
+	LogRequest(ctx context.Context, arg LogRequestParams) error
+	RecordClientRequest(ctx context.Context, arg RecordClientRequestParams) error
+	RecordJobRun(ctx context.Context, arg RecordJobRunParams) error
+	RegisterClient(ctx context.Context, arg RegisterClientParams) error
+	RemoveClient(ctx context.Context, clientID string) error
+	UpdateClientHeartbeat(ctx context.Context, arg UpdateClientHeartbeatParams) error
+	UpsertAccount(ctx context.Context, arg UpsertAccountParams) error
+	UpsertAccountAlias(ctx context.Context, arg UpsertAccountAliasParams) error
+	UpsertNegativeLookup(ctx context.Context, arg UpsertNegativeLookupParams) error
+}
+
+var _ Querier = (*Queries)(nil)
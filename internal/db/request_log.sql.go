@@ -7,25 +7,45 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const cleanOldLogs = `-- name: CleanOldLogs :exec
 DELETE FROM request_log
-WHERE created_at < datetime('now', '-30 days')
+WHERE created_at < ?
 `
 
-func (q *Queries) CleanOldLogs(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, cleanOldLogs)
+func (q *Queries) CleanOldLogs(ctx context.Context, createdAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, cleanOldLogs, createdAt)
 	return err
 }
 
+const getRequestDurationAt = `-- name: GetRequestDurationAt :one
+SELECT duration_ms FROM request_log
+WHERE created_at > ? AND duration_ms IS NOT NULL AND method NOT IN ('GetStats', 'ListNodes')
+ORDER BY duration_ms
+LIMIT 1 OFFSET ?
+`
+
+type GetRequestDurationAtParams struct {
+	CreatedAt time.Time `json:"created_at"`
+	Offset    int64     `json:"offset"`
+}
+
+func (q *Queries) GetRequestDurationAt(ctx context.Context, arg GetRequestDurationAtParams) (*int64, error) {
+	row := q.db.QueryRowContext(ctx, getRequestDurationAt, arg.CreatedAt, arg.Offset)
+	var duration_ms *int64
+	err := row.Scan(&duration_ms)
+	return duration_ms, err
+}
+
 const getRequestStats = `-- name: GetRequestStats :one
 SELECT
     COUNT(*) as total_requests,
     SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END) as successful_requests,
     AVG(duration_ms) as avg_duration_ms
 FROM request_log
-WHERE created_at > datetime('now', '-1 hour')
+WHERE created_at > ? AND method NOT IN ('GetStats', 'ListNodes')
 `
 
 type GetRequestStatsRow struct {
@@ -34,25 +54,123 @@ type GetRequestStatsRow struct {
 	AvgDurationMs      *float64 `json:"avg_duration_ms"`
 }
 
-func (q *Queries) GetRequestStats(ctx context.Context) (GetRequestStatsRow, error) {
-	row := q.db.QueryRowContext(ctx, getRequestStats)
+// admin calls are audited too but aren't api traffic
+func (q *Queries) GetRequestStats(ctx context.Context, createdAt time.Time) (GetRequestStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getRequestStats, createdAt)
 	var i GetRequestStatsRow
 	err := row.Scan(&i.TotalRequests, &i.SuccessfulRequests, &i.AvgDurationMs)
 	return i, err
 }
 
+const getRequestStatsByClient = `-- name: GetRequestStatsByClient :many
+SELECT
+    client_id,
+    COUNT(*) as total_requests,
+    SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END) as successful_requests,
+    AVG(duration_ms) as avg_duration_ms
+FROM request_log
+WHERE created_at > ? AND client_id IS NOT NULL
+GROUP BY client_id
+ORDER BY total_requests DESC
+`
+
+type GetRequestStatsByClientRow struct {
+	ClientID           *string  `json:"client_id"`
+	TotalRequests      int64    `json:"total_requests"`
+	SuccessfulRequests *float64 `json:"successful_requests"`
+	AvgDurationMs      *float64 `json:"avg_duration_ms"`
+}
+
+func (q *Queries) GetRequestStatsByClient(ctx context.Context, createdAt time.Time) ([]GetRequestStatsByClientRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRequestStatsByClient, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetRequestStatsByClientRow{}
+	for rows.Next() {
+		var i GetRequestStatsByClientRow
+		if err := rows.Scan(
+			&i.ClientID,
+			&i.TotalRequests,
+			&i.SuccessfulRequests,
+			&i.AvgDurationMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRequestStatsBySource = `-- name: GetRequestStatsBySource :many
+SELECT
+    source,
+    COUNT(*) as total_requests,
+    SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END) as successful_requests,
+    AVG(duration_ms) as avg_duration_ms
+FROM request_log
+WHERE created_at > ? AND source IS NOT NULL
+GROUP BY source
+ORDER BY total_requests DESC
+`
+
+type GetRequestStatsBySourceRow struct {
+	Source             *string  `json:"source"`
+	TotalRequests      int64    `json:"total_requests"`
+	SuccessfulRequests *float64 `json:"successful_requests"`
+	AvgDurationMs      *float64 `json:"avg_duration_ms"`
+}
+
+func (q *Queries) GetRequestStatsBySource(ctx context.Context, createdAt time.Time) ([]GetRequestStatsBySourceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRequestStatsBySource, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetRequestStatsBySourceRow{}
+	for rows.Next() {
+		var i GetRequestStatsBySourceRow
+		if err := rows.Scan(
+			&i.Source,
+			&i.TotalRequests,
+			&i.SuccessfulRequests,
+			&i.AvgDurationMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const logRequest = `-- name: LogRequest :exec
-INSERT INTO request_log (request_id, client_id, game_name, game_tag, puuid, success, error_message, duration_ms)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO request_log (request_id, method, client_id, game_name, game_tag, puuid, source, success, error_code, error_message, duration_ms)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type LogRequestParams struct {
 	RequestID    string  `json:"request_id"`
+	Method       string  `json:"method"`
 	ClientID     *string `json:"client_id"`
 	GameName     string  `json:"game_name"`
 	GameTag      string  `json:"game_tag"`
 	Puuid        *string `json:"puuid"`
+	Source       *string `json:"source"`
 	Success      bool    `json:"success"`
+	ErrorCode    *string `json:"error_code"`
 	ErrorMessage *string `json:"error_message"`
 	DurationMs   *int64  `json:"duration_ms"`
 }
@@ -60,11 +178,14 @@ type LogRequestParams struct {
 func (q *Queries) LogRequest(ctx context.Context, arg LogRequestParams) error {
 	_, err := q.db.ExecContext(ctx, logRequest,
 		arg.RequestID,
+		arg.Method,
 		arg.ClientID,
 		arg.GameName,
 		arg.GameTag,
 		arg.Puuid,
+		arg.Source,
 		arg.Success,
+		arg.ErrorCode,
 		arg.ErrorMessage,
 		arg.DurationMs,
 	)
@@ -0,0 +1,52 @@
+//go:build cgo && !purego
+
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriver is mattn/go-sqlite3 when cgo is available. build with
+// -tags purego or CGO_ENABLED=0 to use the pure go driver instead
+const sqliteDriver = "sqlite3"
+
+func sqliteDSN(path string) string {
+	return path + "?_journal_mode=WAL&_busy_timeout=5000"
+}
+
+func backupSQLite(ctx context.Context, driverConn any, path string) error {
+	src, ok := driverConn.(*sqlite3.SQLiteConn)
+	if !ok {
+		return fmt.Errorf("unexpected driver connection %T", driverConn)
+	}
+
+	conn, err := (&sqlite3.SQLiteDriver{}).Open(path)
+	if err != nil {
+		return err
+	}
+	dst := conn.(*sqlite3.SQLiteConn)
+	defer dst.Close()
+
+	backup, err := dst.Backup("main", src, "main")
+	if err != nil {
+		return err
+	}
+
+	for {
+		done, err := backup.Step(backupStepPages)
+		if err != nil {
+			backup.Finish()
+			return err
+		}
+		if done {
+			return backup.Finish()
+		}
+		if err := ctx.Err(); err != nil {
+			backup.Finish()
+			return err
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !cgo || purego
+
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"modernc.org/sqlite"
+)
+
+// sqliteDriver is modernc.org/sqlite, which needs no C compiler so the
+// binaries cross-compile with CGO_ENABLED=0
+const sqliteDriver = "sqlite"
+
+// sqliteDSN matches the cgo driver: WAL, a busy timeout, and times written in
+// the format CURRENT_TIMESTAMP comparisons expect
+func sqliteDSN(path string) string {
+	return path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+}
+
+func backupSQLite(ctx context.Context, driverConn any, path string) error {
+	src, ok := driverConn.(interface {
+		NewBackup(dstURI string) (*sqlite.Backup, error)
+	})
+	if !ok {
+		return fmt.Errorf("unexpected driver connection %T", driverConn)
+	}
+
+	backup, err := src.NewBackup(path)
+	if err != nil {
+		return err
+	}
+
+	for {
+		more, err := backup.Step(backupStepPages)
+		if err != nil {
+			backup.Finish()
+			return err
+		}
+		if !more {
+			return backup.Finish()
+		}
+		if err := ctx.Err(); err != nil {
+			backup.Finish()
+			return err
+		}
+	}
+}
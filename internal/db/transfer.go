@@ -0,0 +1,290 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// ImportOptions changes how Import stores rows
+type ImportOptions struct {
+	// Touch stamps imported accounts with the import time so the cleanup job
+	// doesn't delete an old corpus right away. stored accounts are still only
+	// replaced by rows that were newer before they were stamped
+	Touch bool
+}
+
+// transferTable is a table that can be exported to and imported from JSONL
+// or CSV. JSONL uses the json tags of the row type, CSV the columns below
+type transferTable interface {
+	export(ctx context.Context, q Querier, format string, w io.Writer) (int, error)
+	load(ctx context.Context, q Querier, format string, r io.Reader, opts ImportOptions) (int, error)
+}
+
+type table[T any] struct {
+	columns []string
+	list    func(ctx context.Context, q Querier) ([]T, error)
+	// insert must keep the newer of the stored and the imported row
+	insert  func(ctx context.Context, q Querier, row T, opts ImportOptions) error
+	toCSV   func(row T) []string
+	fromCSV func(record []string) (T, error)
+}
+
+// transferTables lists what `master db export` and `master db import` handle,
+// new tables are added here
+var transferTables = map[string]transferTable{
+	"accounts": table[Account]{
+		columns: []string{"puuid", "region", "account_level", "name", "tag", "card", "title", "updated_at", "created_at"},
+		list: func(ctx context.Context, q Querier) ([]Account, error) {
+			return q.ListAccounts(ctx)
+		},
+		insert: func(ctx context.Context, q Querier, a Account, opts ImportOptions) error {
+			a.UpdatedAt, a.CreatedAt = a.UpdatedAt.UTC(), a.CreatedAt.UTC()
+			if opts.Touch {
+				stored, err := q.GetAccountByPUUID(ctx, a.Puuid)
+				if err == nil && !a.UpdatedAt.After(stored.UpdatedAt) {
+					return nil
+				}
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+				a.UpdatedAt = time.Now().UTC()
+			}
+			return q.ImportAccount(ctx, ImportAccountParams(a))
+		},
+		toCSV: func(a Account) []string {
+			return []string{a.Puuid, a.Region, strconv.FormatInt(a.AccountLevel, 10), a.Name, a.Tag, a.Card, a.Title, formatTime(a.UpdatedAt), formatTime(a.CreatedAt)}
+		},
+		fromCSV: func(record []string) (Account, error) {
+			var a Account
+			var err error
+			a.Puuid, a.Region, a.Name, a.Tag, a.Card, a.Title = record[0], record[1], record[3], record[4], record[5], record[6]
+			if a.AccountLevel, err = strconv.ParseInt(record[2], 10, 64); err != nil {
+				return a, fmt.Errorf("invalid account_level: %w", err)
+			}
+			if a.UpdatedAt, err = parseTime(record[7]); err != nil {
+				return a, fmt.Errorf("invalid updated_at: %w", err)
+			}
+			if a.CreatedAt, err = parseTime(record[8]); err != nil {
+				return a, fmt.Errorf("invalid created_at: %w", err)
+			}
+			return a, nil
+		},
+	},
+	"account_aliases": table[AccountAlias]{
+		columns: []string{"puuid", "name", "tag", "first_seen", "last_seen"},
+		list: func(ctx context.Context, q Querier) ([]AccountAlias, error) {
+			return q.ListAccountAliases(ctx)
+		},
+		// an alias already stored widens to cover both sightings
+		insert: func(ctx context.Context, q Querier, a AccountAlias, opts ImportOptions) error {
+			a.FirstSeen, a.LastSeen = a.FirstSeen.UTC(), a.LastSeen.UTC()
+			return q.ImportAccountAlias(ctx, ImportAccountAliasParams(a))
+		},
+		toCSV: func(a AccountAlias) []string {
+			return []string{a.Puuid, a.Name, a.Tag, formatTime(a.FirstSeen), formatTime(a.LastSeen)}
+		},
+		fromCSV: func(record []string) (AccountAlias, error) {
+			var a AccountAlias
+			var err error
+			a.Puuid, a.Name, a.Tag = record[0], record[1], record[2]
+			if a.FirstSeen, err = parseTime(record[3]); err != nil {
+				return a, fmt.Errorf("invalid first_seen: %w", err)
+			}
+			if a.LastSeen, err = parseTime(record[4]); err != nil {
+				return a, fmt.Errorf("invalid last_seen: %w", err)
+			}
+			return a, nil
+		},
+	},
+	// snapshot ids aren't exported, the importing database numbers them
+	"account_snapshots": table[AccountSnapshot]{
+		columns: []string{"puuid", "account_level", "card", "title", "created_at"},
+		list: func(ctx context.Context, q Querier) ([]AccountSnapshot, error) {
+			return q.ListAccountSnapshots(ctx)
+		},
+		// an identical snapshot taken the same second is already stored
+		insert: func(ctx context.Context, q Querier, a AccountSnapshot, opts ImportOptions) error {
+			return q.ImportAccountSnapshot(ctx, ImportAccountSnapshotParams{
+				Puuid:        a.Puuid,
+				AccountLevel: a.AccountLevel,
+				Card:         a.Card,
+				Title:        a.Title,
+				CreatedAt:    a.CreatedAt.UTC(),
+			})
+		},
+		toCSV: func(a AccountSnapshot) []string {
+			return []string{a.Puuid, strconv.FormatInt(a.AccountLevel, 10), a.Card, a.Title, formatTime(a.CreatedAt)}
+		},
+		fromCSV: func(record []string) (AccountSnapshot, error) {
+			var a AccountSnapshot
+			var err error
+			a.Puuid, a.Card, a.Title = record[0], record[2], record[3]
+			if a.AccountLevel, err = strconv.ParseInt(record[1], 10, 64); err != nil {
+				return a, fmt.Errorf("invalid account_level: %w", err)
+			}
+			if a.CreatedAt, err = parseTime(record[4]); err != nil {
+				return a, fmt.Errorf("invalid created_at: %w", err)
+			}
+			return a, nil
+		},
+	},
+}
+
+// TransferTables names the tables that can be exported and imported
+func TransferTables() []string {
+	names := make([]string, 0, len(transferTables))
+	for name := range transferTables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Export writes every row of a table to w and returns how many it wrote
+func (d *Database) Export(ctx context.Context, tableName, format string, w io.Writer) (int, error) {
+	t, err := transferTableFor(tableName, format)
+	if err != nil {
+		return 0, err
+	}
+	return t.export(ctx, d, format, w)
+}
+
+// Import reads rows from r into a table in one transaction. rows that already
+// exist are only overwritten by newer ones
+func (d *Database) Import(ctx context.Context, tableName, format string, r io.Reader, opts ImportOptions) (int, error) {
+	t, err := transferTableFor(tableName, format)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = d.InTx(ctx, func(q Querier) error {
+		count, err = t.load(ctx, q, format, r, opts)
+		return err
+	})
+	return count, err
+}
+
+func transferTableFor(tableName, format string) (transferTable, error) {
+	if format != FormatJSONL && format != FormatCSV {
+		return nil, fmt.Errorf("unknown format %q, expected %s or %s", format, FormatJSONL, FormatCSV)
+	}
+	t, ok := transferTables[tableName]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %q, expected one of %v", tableName, TransferTables())
+	}
+	return t, nil
+}
+
+func (t table[T]) export(ctx context.Context, q Querier, format string, w io.Writer) (int, error) {
+	rows, err := t.list(ctx, q)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	if format == FormatJSONL {
+		buf := bufio.NewWriter(w)
+		encoder := json.NewEncoder(buf)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return 0, err
+			}
+		}
+		return len(rows), buf.Flush()
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(t.columns); err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		if err := writer.Write(t.toCSV(row)); err != nil {
+			return 0, err
+		}
+	}
+	writer.Flush()
+	return len(rows), writer.Error()
+}
+
+func (t table[T]) load(ctx context.Context, q Querier, format string, r io.Reader, opts ImportOptions) (int, error) {
+	next := t.jsonlReader(r)
+	if format == FormatCSV {
+		var err error
+		if next, err = t.csvReader(r); err != nil {
+			return 0, err
+		}
+	}
+
+	count := 0
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("row %d: %w", count+1, err)
+		}
+		if err := t.insert(ctx, q, row, opts); err != nil {
+			return count, fmt.Errorf("row %d: %w", count+1, err)
+		}
+		count++
+	}
+}
+
+func (t table[T]) jsonlReader(r io.Reader) func() (T, error) {
+	decoder := json.NewDecoder(r)
+	return func() (T, error) {
+		var row T
+		err := decoder.Decode(&row)
+		return row, err
+	}
+}
+
+// csvReader checks the header matches the table so columns can't silently
+// land in the wrong field
+func (t table[T]) csvReader(r io.Reader) (func() (T, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(t.columns)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	for i, column := range t.columns {
+		if header[i] != column {
+			return nil, fmt.Errorf("unexpected csv header %v, expected %v", header, t.columns)
+		}
+	}
+
+	return func() (T, error) {
+		record, err := reader.Read()
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return t.fromCSV(record)
+	}, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	return t.UTC(), err
+}
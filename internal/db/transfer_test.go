@@ -0,0 +1,280 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	for _, format := range []string{FormatJSONL, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			forEachEngine(t, func(t *testing.T, source *Database) {
+				ctx := context.Background()
+
+				account := UpsertAccountParams{Puuid: "puuid-1", Region: "eu", AccountLevel: 10, Name: "Comma, \"Quote\"", Tag: "EUW", Card: "card", Title: "title"}
+				if err := source.UpsertAccount(ctx, account); err != nil {
+					t.Fatalf("failed to upsert account: %v", err)
+				}
+
+				var buf bytes.Buffer
+				if n, err := source.Export(ctx, "accounts", format, &buf); err != nil || n != 1 {
+					t.Fatalf("export wrote %d rows, err = %v", n, err)
+				}
+
+				target := openTestSQLite(t)
+				if n, err := target.Import(ctx, "accounts", format, bytes.NewReader(buf.Bytes()), ImportOptions{}); err != nil || n != 1 {
+					t.Fatalf("import read %d rows, err = %v", n, err)
+				}
+
+				got, err := target.GetAccountByPUUID(ctx, "puuid-1")
+				if err != nil {
+					t.Fatalf("imported account missing: %v", err)
+				}
+				if got.Name != account.Name || got.AccountLevel != account.AccountLevel {
+					t.Errorf("got %q level %d, want %q level %d", got.Name, got.AccountLevel, account.Name, account.AccountLevel)
+				}
+			})
+		})
+	}
+}
+
+func TestImportKeepsNewerRows(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *Database) {
+		ctx := context.Background()
+
+		if err := database.UpsertAccount(ctx, UpsertAccountParams{Puuid: "puuid-1", Region: "eu", AccountLevel: 20, Name: "Current", Tag: "EUW"}); err != nil {
+			t.Fatalf("failed to upsert account: %v", err)
+		}
+
+		old := time.Now().UTC().Add(-24 * time.Hour).Format(time.RFC3339)
+		csv := "puuid,region,account_level,name,tag,card,title,updated_at,created_at\n" +
+			"puuid-1,eu,10,Old,EUW,,," + old + "," + old + "\n" +
+			"puuid-2,na,5,New,NA1,,," + old + "," + old + "\n"
+
+		if n, err := database.Import(ctx, "accounts", FormatCSV, bytes.NewBufferString(csv), ImportOptions{}); err != nil || n != 2 {
+			t.Fatalf("import read %d rows, err = %v", n, err)
+		}
+
+		current, err := database.GetAccountByPUUID(ctx, "puuid-1")
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+		if current.Name != "Current" {
+			t.Errorf("older import overwrote the stored account, name = %q", current.Name)
+		}
+
+		if _, err := database.GetAccountByPUUID(ctx, "puuid-2"); err != nil {
+			t.Errorf("new account was not imported: %v", err)
+		}
+	})
+}
+
+func TestImportSameSecond(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *Database) {
+		ctx := context.Background()
+
+		if err := database.UpsertAccount(ctx, UpsertAccountParams{Puuid: "puuid-1", Region: "eu", AccountLevel: 20, Name: "Current", Tag: "EUW"}); err != nil {
+			t.Fatalf("failed to upsert account: %v", err)
+		}
+		stored, err := database.GetAccountByPUUID(ctx, "puuid-1")
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+
+		// written by the driver in another format than CURRENT_TIMESTAMP, but
+		// not newer
+		same := stored.UpdatedAt.UTC().Format(time.RFC3339)
+		csv := "puuid,region,account_level,name,tag,card,title,updated_at,created_at\n" +
+			"puuid-1,eu,10,Import,EUW,,," + same + "," + same + "\n"
+		if _, err := database.Import(ctx, "accounts", FormatCSV, bytes.NewBufferString(csv), ImportOptions{}); err != nil {
+			t.Fatalf("import failed: %v", err)
+		}
+
+		current, err := database.GetAccountByPUUID(ctx, "puuid-1")
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+		if current.Name != "Current" {
+			t.Errorf("import from the same second overwrote the stored account, name = %q", current.Name)
+		}
+	})
+}
+
+func TestImportRejectsBadInput(t *testing.T) {
+	database := openTestSQLite(t)
+	ctx := context.Background()
+
+	if _, err := database.Import(ctx, "accounts", FormatCSV, bytes.NewBufferString("puuid,name\n"), ImportOptions{}); err == nil {
+		t.Error("import accepted a csv with the wrong header")
+	}
+	if _, err := database.Import(ctx, "players", FormatJSONL, bytes.NewBufferString(""), ImportOptions{}); err == nil {
+		t.Error("import accepted an unknown table")
+	}
+
+	// a failing row rolls back the rows before it
+	jsonl := `{"puuid":"puuid-1","region":"eu","account_level":1,"updated_at":"2024-01-01T00:00:00Z","created_at":"2024-01-01T00:00:00Z"}` + "\nnot json\n"
+	if _, err := database.Import(ctx, "accounts", FormatJSONL, bytes.NewBufferString(jsonl), ImportOptions{}); err == nil {
+		t.Fatal("import accepted invalid json")
+	}
+	if _, err := database.GetAccountByPUUID(ctx, "puuid-1"); err == nil {
+		t.Error("rows before the failing one were kept")
+	}
+}
+
+func TestBackupInto(t *testing.T) {
+	database := openTestSQLite(t)
+	ctx := context.Background()
+
+	if err := database.UpsertAccount(ctx, UpsertAccountParams{Puuid: "puuid-1", Region: "eu", Name: "Player", Tag: "EUW"}); err != nil {
+		t.Fatalf("failed to upsert account: %v", err)
+	}
+
+	dir := t.TempDir()
+	path, err := database.BackupInto(ctx, dir, 1)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	backup, err := New(path)
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer backup.Close()
+
+	if _, err := backup.GetAccountByPUUID(ctx, "puuid-1"); err != nil {
+		t.Errorf("account missing from backup: %v", err)
+	}
+
+	if err := database.Backup(ctx, path); err == nil {
+		t.Error("backup overwrote an existing file")
+	}
+
+	// older backups beyond keep are pruned
+	stale := filepath.Join(dir, backupPrefix+"20000101-000000"+backupSuffix)
+	if err := database.Backup(ctx, stale); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if err := pruneBackups(dir, 1); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, backupPrefix+"*"+backupSuffix)); len(matches) != 1 || matches[0] != path {
+		t.Errorf("backups after prune = %v, want only %s", matches, path)
+	}
+}
+
+func TestImportTouch(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *Database) {
+		ctx := context.Background()
+
+		if err := database.UpsertAccount(ctx, UpsertAccountParams{Puuid: "puuid-1", Region: "eu", AccountLevel: 20, Name: "Current", Tag: "EUW"}); err != nil {
+			t.Fatalf("failed to upsert account: %v", err)
+		}
+
+		old := time.Now().UTC().Add(-90 * 24 * time.Hour).Format(time.RFC3339)
+		csv := "puuid,region,account_level,name,tag,card,title,updated_at,created_at\n" +
+			"puuid-1,eu,10,Old,EUW,,," + old + "," + old + "\n" +
+			"puuid-2,na,5,Seed,NA1,,," + old + "," + old + "\n"
+
+		if _, err := database.Import(ctx, "accounts", FormatCSV, bytes.NewBufferString(csv), ImportOptions{Touch: true}); err != nil {
+			t.Fatalf("import failed: %v", err)
+		}
+
+		// the stamp doesn't let an old row replace a newer one
+		current, err := database.GetAccountByPUUID(ctx, "puuid-1")
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+		if current.Name != "Current" {
+			t.Errorf("touched import overwrote the stored account, name = %q", current.Name)
+		}
+
+		seed, err := database.GetAccountByPUUID(ctx, "puuid-2")
+		if err != nil {
+			t.Fatalf("seeded account missing: %v", err)
+		}
+		if time.Since(seed.UpdatedAt) > time.Minute {
+			t.Errorf("updated_at = %v, want the import time", seed.UpdatedAt)
+		}
+
+		// the cleanup job with the default retention keeps the seed
+		if err := database.CleanOldAccounts(ctx, time.Now().UTC().Add(-7*24*time.Hour)); err != nil {
+			t.Fatalf("failed to clean accounts: %v", err)
+		}
+		if _, err := database.GetAccountByPUUID(ctx, "puuid-2"); err != nil {
+			t.Errorf("cleanup deleted the touched seed: %v", err)
+		}
+	})
+}
+
+func TestExportImportHistory(t *testing.T) {
+	for _, format := range []string{FormatJSONL, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			forEachEngine(t, func(t *testing.T, source *Database) {
+				ctx := context.Background()
+
+				for _, alias := range []UpsertAccountAliasParams{{Puuid: "puuid-1", Name: "Old", Tag: "EUW"}, {Puuid: "puuid-1", Name: "New", Tag: "EUW"}} {
+					if err := source.UpsertAccountAlias(ctx, alias); err != nil {
+						t.Fatalf("failed to upsert alias: %v", err)
+					}
+				}
+				for _, level := range []int64{10, 11} {
+					if err := source.InsertAccountSnapshot(ctx, InsertAccountSnapshotParams{Puuid: "puuid-1", AccountLevel: level, Card: "card", Title: "title"}); err != nil {
+						t.Fatalf("failed to insert snapshot: %v", err)
+					}
+				}
+
+				target := openTestSQLite(t)
+				for _, table := range []string{"account_aliases", "account_snapshots"} {
+					var buf bytes.Buffer
+					if n, err := source.Export(ctx, table, format, &buf); err != nil || n != 2 {
+						t.Fatalf("export of %s wrote %d rows, err = %v", table, n, err)
+					}
+					// importing twice must not duplicate anything
+					for range 2 {
+						if n, err := target.Import(ctx, table, format, bytes.NewReader(buf.Bytes()), ImportOptions{}); err != nil || n != 2 {
+							t.Fatalf("import of %s read %d rows, err = %v", table, n, err)
+						}
+					}
+				}
+
+				aliases, err := target.GetAccountAliases(ctx, "puuid-1")
+				if err != nil || len(aliases) != 2 {
+					t.Fatalf("got %d aliases, err = %v, want 2", len(aliases), err)
+				}
+
+				snapshots, err := target.GetAccountSnapshots(ctx, GetAccountSnapshotsParams{Puuid: "puuid-1", Limit: 10})
+				if err != nil || len(snapshots) != 2 {
+					t.Fatalf("got %d snapshots, err = %v, want 2", len(snapshots), err)
+				}
+			})
+		})
+	}
+}
+
+func TestImportOlderSnapshots(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *Database) {
+		ctx := context.Background()
+
+		if err := database.InsertAccountSnapshot(ctx, InsertAccountSnapshotParams{Puuid: "puuid-1", AccountLevel: 50, Card: "card", Title: "title"}); err != nil {
+			t.Fatalf("failed to insert snapshot: %v", err)
+		}
+
+		old := time.Now().UTC().Add(-30 * 24 * time.Hour).Format(time.RFC3339)
+		csv := "puuid,account_level,card,title,created_at\npuuid-1,10,card,title," + old + "\n"
+		if _, err := database.Import(ctx, "account_snapshots", FormatCSV, bytes.NewBufferString(csv), ImportOptions{}); err != nil {
+			t.Fatalf("import failed: %v", err)
+		}
+
+		// the imported snapshot has the higher id but is older
+		latest, err := database.GetLatestAccountSnapshot(ctx, "puuid-1")
+		if err != nil {
+			t.Fatalf("failed to get latest snapshot: %v", err)
+		}
+		if latest.AccountLevel != 50 {
+			t.Errorf("latest snapshot is level %d, want 50", latest.AccountLevel)
+		}
+	})
+}
@@ -0,0 +1,292 @@
+// Package integration runs a master and in-process nodes backed by fake riot
+// clients and PD servers, so the whole lookup path from the Connect API down
+// to riot can be tested in one process
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"connectrpc.com/connect"
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/gen/v1connect"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/api"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/cache"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/lcu"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/lcu/lcutest"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/protocol"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/valorant"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/valorant/pdtest"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/version"
+)
+
+// waitTimeout bounds every wait for the master to see a node change
+const waitTimeout = 10 * time.Second
+
+// Options tune the master, zero values use the master's defaults
+type Options struct {
+	Freshness      api.Freshness
+	RequestTimeout time.Duration
+	Logger         *zap.SugaredLogger
+}
+
+// Harness is a running master. everything it starts is stopped when the test
+// ends
+type Harness struct {
+	t      testing.TB
+	logger *zap.SugaredLogger
+
+	Database  *db.Database
+	TCP       *protocol.Server
+	Accounts  cache.Cache[*v1.AccountData]
+	Negatives cache.Cache[*api.NegativeLookup]
+	API       v1connect.ValorantAPIClient
+	Admin     v1connect.AdminAPIClient
+
+	http    *httptest.Server
+	players []lcutest.Player
+
+	mu    sync.Mutex
+	nodes []*Node
+}
+
+// New starts a master on an in-memory SQLite database with the Connect API
+// served over httptest
+func New(t testing.TB, opts Options) *Harness {
+	t.Helper()
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	freshness := opts.Freshness
+	if freshness == (api.Freshness{}) {
+		freshness = api.Freshness{Fresh: time.Hour, Stale: 24 * time.Hour, NotFound: 10 * time.Minute}
+	}
+
+	database, err := db.New(db.MemoryPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	tcpServer, err := protocol.NewServer(0, database, logger)
+	if err != nil {
+		database.Close()
+		t.Fatalf("failed to start TCP server: %v", err)
+	}
+	if opts.RequestTimeout > 0 {
+		tcpServer.SetRequestTimeout(opts.RequestTimeout)
+	}
+	go tcpServer.Start()
+
+	h := &Harness{
+		t:         t,
+		logger:    logger,
+		Database:  database,
+		TCP:       tcpServer,
+		Accounts:  cache.NewMemory(cache.MemoryOptions[*v1.AccountData]{TTL: freshness.Stale}),
+		Negatives: cache.NewMemory(cache.MemoryOptions[*api.NegativeLookup]{TTL: freshness.NotFound}),
+	}
+
+	mux := http.NewServeMux()
+	apiService := api.NewService(tcpServer, h.Accounts, h.Negatives, database, freshness, logger)
+	path, handler := v1connect.NewValorantAPIHandler(apiService, connect.WithInterceptors(api.NewAuditInterceptor(database, logger)))
+	mux.Handle(path, handler)
+	adminPath, adminHandler := v1connect.NewAdminAPIHandler(api.NewAdminService(database, tcpServer, logger))
+	mux.Handle(adminPath, adminHandler)
+
+	h.http = httptest.NewServer(mux)
+	h.API = v1connect.NewValorantAPIClient(h.http.Client(), h.http.URL)
+	h.Admin = v1connect.NewAdminAPIClient(h.http.Client(), h.http.URL)
+
+	t.Cleanup(h.Close)
+	return h
+}
+
+// Close stops the nodes, then the master
+func (h *Harness) Close() {
+	h.mu.Lock()
+	nodes := h.nodes
+	h.nodes = nil
+	h.mu.Unlock()
+
+	for _, node := range nodes {
+		node.Stop()
+	}
+
+	h.http.Close()
+	h.TCP.Stop()
+	h.Accounts.Stop()
+	h.Negatives.Stop()
+	h.Database.Close()
+}
+
+// AddPlayer makes an account findable by the riot client of every node,
+// including nodes added later
+func (h *Harness) AddPlayer(p lcutest.Player) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.players = append(h.players, p)
+	for _, node := range h.nodes {
+		node.LCU.AddPlayer(p)
+	}
+}
+
+// GetAccount looks an account up through the Connect API
+func (h *Harness) GetAccount(req *v1.GetAccountRequest) *v1.GetAccountResponse {
+	h.t.Helper()
+
+	resp, err := h.API.GetAccount(context.Background(), connect.NewRequest(req))
+	if err != nil {
+		h.t.Fatalf("GetAccount(%s#%s) failed: %v", req.Name, req.Tag, err)
+	}
+	return resp.Msg
+}
+
+// Node looks a node up in ListNodes, nil if the master doesn't know it
+func (h *Harness) Node(clientID string) *v1.NodeInfo {
+	h.t.Helper()
+
+	resp, err := h.Admin.ListNodes(context.Background(), connect.NewRequest(&v1.ListNodesRequest{}))
+	if err != nil {
+		h.t.Fatalf("ListNodes failed: %v", err)
+	}
+	for _, node := range resp.Msg.Data {
+		if node.ClientId == clientID {
+			return node
+		}
+	}
+	return nil
+}
+
+// WaitStatus waits until the master reports the node with status, e.g.
+// "ready" or "logged_out"
+func (h *Harness) WaitStatus(node *Node, status string) {
+	h.t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		info := h.Node(node.ID)
+		if info != nil && info.Status == status && h.TCP.IsConnected(node.ID) {
+			return
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("node %s never became %s, last seen as %v", node.ID, status, info)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// WaitDisconnected waits until the master dropped the node's connection
+func (h *Harness) WaitDisconnected(node *Node) {
+	h.t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+	for h.TCP.IsConnected(node.ID) {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("node %s is still connected", node.ID)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Node is an in-process client node with its own fake riot client and PD
+// server, wired up like cmd/client
+type Node struct {
+	ID  string
+	LCU *lcutest.Server
+	PD  *pdtest.Server
+
+	lockfile string
+	resolver *lcu.Resolver
+	watcher  *lcu.Watcher
+	client   *protocol.Client
+	running  chan struct{}
+	stop     sync.Once
+}
+
+// NewNode creates a node's fakes without connecting it, so a test can set the
+// riot client up before the master sees its first heartbeat
+func (h *Harness) NewNode() *Node {
+	h.t.Helper()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node := &Node{
+		ID:       fmt.Sprintf("node-%d", len(h.nodes)+1),
+		LCU:      lcutest.NewServer(),
+		PD:       pdtest.NewServer(pdtest.Fixtures()),
+		lockfile: filepath.Join(h.t.TempDir(), "lockfile"),
+	}
+	for _, p := range h.players {
+		node.LCU.AddPlayer(p)
+	}
+	if err := node.LCU.WriteLockfile(node.lockfile); err != nil {
+		h.t.Fatalf("failed to write lockfile: %v", err)
+	}
+
+	journal, _ := lcu.OpenJournal("")
+	logger := h.logger.With("node", node.ID)
+	node.resolver = lcu.NewResolver(nil, valorant.NewClient(node.PD.URL(), nil, logger), journal, logger)
+	node.watcher = lcu.NewWatcher(node.lockfile, lcu.DefaultWatchInterval, node.resolver, logger)
+	node.client = protocol.NewClient(h.TCP.Addr().String(), node.ID, version.Version, node.resolver, logger)
+
+	h.nodes = append(h.nodes, node)
+	return node
+}
+
+// AddNode starts a node and waits until the master routes requests to it
+func (h *Harness) AddNode() *Node {
+	h.t.Helper()
+
+	node := h.NewNode()
+	h.StartNode(node)
+	h.WaitStatus(node, "ready")
+	return node
+}
+
+// StartNode connects a node created with NewNode to the master
+func (h *Harness) StartNode(node *Node) {
+	h.t.Helper()
+
+	node.watcher.Start()
+	if err := node.client.Connect(); err != nil {
+		h.t.Fatalf("node %s failed to connect: %v", node.ID, err)
+	}
+
+	node.running = make(chan struct{})
+	go func() {
+		defer close(node.running)
+		node.client.Run()
+	}()
+}
+
+// Stop disconnects the node from the master and shuts its fakes down
+func (n *Node) Stop() {
+	n.stop.Do(func() {
+		if n.running != nil {
+			n.client.Stop()
+			<-n.running
+			n.watcher.Stop()
+		}
+		n.LCU.Close()
+		n.PD.Close()
+	})
+}
+
+// Sent counts the friend requests the node sent, one per lookup that reached
+// its riot client
+func (n *Node) Sent() int {
+	return n.LCU.Hits(http.MethodPost, "/chat/v4/friendrequests")
+}
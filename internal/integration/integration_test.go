@@ -0,0 +1,306 @@
+package integration
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/api"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/cache"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/lcu/lcutest"
+)
+
+// the accounts the built-in PD fixtures know, see testdata/players.json
+var (
+	zcerno    = lcutest.Player{GameName: "zcerno", GameTag: "3137", PUUID: "e83573ec-ec6f-5034-9a38-ed0ccf8dbb1b", Region: "eu2"}
+	noMatches = lcutest.Player{GameName: "abcd", GameTag: "1234", PUUID: "0f5c7a5e-3b4d-4c8e-9a21-6d2f1b7c8e90", Region: "na1"}
+)
+
+func newHarness(t *testing.T, opts Options) *Harness {
+	h := New(t, opts)
+	h.AddPlayer(zcerno)
+	h.AddPlayer(noMatches)
+	return h
+}
+
+func lookup(name, tag string) *v1.GetAccountRequest {
+	return &v1.GetAccountRequest{Name: name, Tag: tag}
+}
+
+func TestLookup(t *testing.T) {
+	h := newHarness(t, Options{})
+	node := h.AddNode()
+
+	resp := h.GetAccount(lookup("zcerno", "3137"))
+	if resp.Status != 200 || resp.Source != api.SourceNode {
+		t.Fatalf("got status %d from %q: %s", resp.Status, resp.Source, resp.Error)
+	}
+	data := resp.Data
+	if data.Puuid != zcerno.PUUID || data.Region != "eu2" || data.AccountLevel != 50 || data.Card != "a372c539-41c7-6a41-7064-80a61ccd597b" || data.Title != "ed96f7bd-4eed-de28-8c93-40bd313a3157" {
+		t.Errorf("unexpected account %+v", data)
+	}
+	if pending := node.LCU.FriendRequests(); len(pending) != 0 {
+		t.Errorf("node left friend requests behind: %v", pending)
+	}
+
+	// served from the cache, then from the database, without the node
+	if resp := h.GetAccount(lookup("zcerno", "3137")); resp.Source != api.SourceMemory {
+		t.Errorf("second lookup came from %q, want %q", resp.Source, api.SourceMemory)
+	}
+	h.Accounts.Delete(cache.MakeKey("zcerno", "3137"))
+	if resp := h.GetAccount(lookup("zcerno", "3137")); resp.Source != api.SourceDatabase {
+		t.Errorf("lookup after eviction came from %q, want %q", resp.Source, api.SourceDatabase)
+	}
+	if sent := node.Sent(); sent != 1 {
+		t.Errorf("node sent %d friend requests, want 1", sent)
+	}
+
+	req := lookup("zcerno", "3137")
+	req.ForceRefresh = true
+	if resp := h.GetAccount(req); resp.Status != 200 || resp.Source != api.SourceNode {
+		t.Errorf("forced refresh got status %d from %q", resp.Status, resp.Source)
+	}
+	if sent := node.Sent(); sent != 2 {
+		t.Errorf("node sent %d friend requests after a forced refresh, want 2", sent)
+	}
+
+	if info := h.Node(node.ID); info.RequestsServed != 2 || info.Failures != 0 {
+		t.Errorf("node served %d requests with %d failures, want 2 and 0", info.RequestsServed, info.Failures)
+	}
+}
+
+func TestNegativeLookup(t *testing.T) {
+	h := newHarness(t, Options{})
+	node := h.AddNode()
+
+	resp := h.GetAccount(lookup("abcd", "1234"))
+	if resp.Status != 404 || resp.Source != api.SourceNode {
+		t.Fatalf("got status %d from %q, want 404 from the node", resp.Status, resp.Source)
+	}
+
+	if resp := h.GetAccount(lookup("abcd", "1234")); resp.Status != 404 || resp.Source != api.SourceMemory {
+		t.Errorf("second lookup got status %d from %q, want 404 from memory", resp.Status, resp.Source)
+	}
+	if sent := node.Sent(); sent != 1 {
+		t.Errorf("node sent %d friend requests, want 1", sent)
+	}
+
+	req := lookup("abcd", "1234")
+	req.ForceRefresh = true
+	if resp := h.GetAccount(req); resp.Source != api.SourceNode {
+		t.Errorf("forced refresh came from %q, want %q", resp.Source, api.SourceNode)
+	}
+
+	// not found is an answer, not a node failure
+	if info := h.Node(node.ID); info.Failures != 0 {
+		t.Errorf("node has %d failures, want 0", info.Failures)
+	}
+}
+
+func TestConcurrentLookupsShareResolve(t *testing.T) {
+	h := newHarness(t, Options{})
+	node := h.AddNode()
+	node.LCU.SetDelay("/chat/v4/friendrequests", 200*time.Millisecond)
+
+	var wg sync.WaitGroup
+	responses := make([]*v1.GetAccountResponse, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// riot ids differing in case are the same account
+			name := "zcerno"
+			if i%2 == 1 {
+				name = "ZCERNO"
+			}
+			responses[i] = h.GetAccount(lookup(name, "3137"))
+		}()
+	}
+	wg.Wait()
+
+	for i, resp := range responses {
+		if resp.Status != 200 {
+			t.Errorf("lookup %d got status %d: %s", i, resp.Status, resp.Error)
+		}
+	}
+	if sent := node.Sent(); sent != 1 {
+		t.Errorf("node sent %d friend requests, want 1", sent)
+	}
+}
+
+func TestNoAvailableNodes(t *testing.T) {
+	h := newHarness(t, Options{})
+
+	resp := h.GetAccount(lookup("zcerno", "3137"))
+	if resp.Status != 500 || !strings.Contains(resp.Error, "no available clients") {
+		t.Errorf("without nodes got status %d: %s", resp.Status, resp.Error)
+	}
+
+	// a connected node whose riot client is logged out isn't routed to
+	node := h.NewNode()
+	node.LCU.SetLoggedOut(true)
+	h.StartNode(node)
+	h.WaitStatus(node, "logged_out")
+
+	resp = h.GetAccount(lookup("zcerno", "3137"))
+	if resp.Status != 500 || !strings.Contains(resp.Error, "no available clients") {
+		t.Errorf("with a logged out node got status %d: %s", resp.Status, resp.Error)
+	}
+	if sent := node.Sent(); sent != 0 {
+		t.Errorf("logged out node sent %d friend requests", sent)
+	}
+}
+
+// served returns the node whose riot client sent the friend requests and the
+// one that sent none
+func served(t *testing.T, a, b *Node) (busy, idle *Node) {
+	t.Helper()
+	switch {
+	case a.Sent() > 0 && b.Sent() == 0:
+		return a, b
+	case b.Sent() > 0 && a.Sent() == 0:
+		return b, a
+	}
+	t.Fatalf("%s sent %d friend requests and %s %d, want exactly one of them", a.ID, a.Sent(), b.ID, b.Sent())
+	return nil, nil
+}
+
+func TestFailoverOnDisconnect(t *testing.T) {
+	h := newHarness(t, Options{})
+	a, b := h.AddNode(), h.AddNode()
+
+	if resp := h.GetAccount(lookup("zcerno", "3137")); resp.Status != 200 {
+		t.Fatalf("got status %d: %s", resp.Status, resp.Error)
+	}
+	gone, left := served(t, a, b)
+
+	gone.Stop()
+	h.WaitDisconnected(gone)
+
+	for range 3 {
+		req := lookup("zcerno", "3137")
+		req.ForceRefresh = true
+		if resp := h.GetAccount(req); resp.Status != 200 {
+			t.Fatalf("after %s left got status %d: %s", gone.ID, resp.Status, resp.Error)
+		}
+	}
+	if sent := left.Sent(); sent != 3 {
+		t.Errorf("%s sent %d friend requests, want 3", left.ID, sent)
+	}
+}
+
+func TestFailoverOnRateLimit(t *testing.T) {
+	h := newHarness(t, Options{})
+	a, b := h.AddNode(), h.AddNode()
+	a.PD.Fail("/match-history/", http.StatusTooManyRequests, 0)
+	b.PD.Fail("/match-history/", http.StatusTooManyRequests, 0)
+
+	if resp := h.GetAccount(lookup("zcerno", "3137")); resp.Status != 429 {
+		t.Fatalf("got status %d, want 429: %s", resp.Status, resp.Error)
+	}
+	limited, other := served(t, a, b)
+
+	// riot recovered, but the master stays off the limited node until it
+	// reports back as ready
+	a.PD.ClearFailures()
+	b.PD.ClearFailures()
+
+	if resp := h.GetAccount(lookup("zcerno", "3137")); resp.Status != 200 {
+		t.Fatalf("got status %d: %s", resp.Status, resp.Error)
+	}
+	if sent := other.Sent(); sent != 1 {
+		t.Errorf("%s sent %d friend requests, want 1", other.ID, sent)
+	}
+	if sent := limited.Sent(); sent != 1 {
+		t.Errorf("rate limited %s sent %d friend requests, want 1", limited.ID, sent)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	h := newHarness(t, Options{RequestTimeout: 200 * time.Millisecond})
+	node := h.AddNode()
+	node.LCU.SetDelay("/chat/v4/friendrequests", time.Second)
+
+	resp := h.GetAccount(lookup("abcd", "1234"))
+	if resp.Status != 500 || !strings.Contains(resp.Error, "timed out") {
+		t.Errorf("got status %d: %s", resp.Status, resp.Error)
+	}
+	if info := h.Node(node.ID); info.Failures != 1 {
+		t.Errorf("node has %d failures, want 1", info.Failures)
+	}
+
+	// the node recovers, the next lookup neither waits for the timed out one
+	// nor gets its late answer
+	node.LCU.SetDelay("/chat/v4/friendrequests", 0)
+	h.TCP.SetRequestTimeout(5 * time.Second)
+	started := time.Now()
+	resp = h.GetAccount(lookup("zcerno", "3137"))
+	if resp.Status != 200 || resp.Data.Puuid != zcerno.PUUID {
+		t.Fatalf("lookup after a timeout got status %d: %s %+v", resp.Status, resp.Error, resp.Data)
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("lookup after a timeout took %v, queued behind the timed out one", elapsed)
+	}
+
+	// the late answer to the first lookup is dropped rather than handed to
+	// the next one
+	time.Sleep(time.Second)
+	req := lookup("zcerno", "3137")
+	req.ForceRefresh = true
+	if resp := h.GetAccount(req); resp.Status != 200 || resp.Data.Puuid != zcerno.PUUID {
+		t.Errorf("lookup after the late answer got status %d: %s %+v", resp.Status, resp.Error, resp.Data)
+	}
+	if info := h.Node(node.ID); info.RequestsServed != 3 || info.Failures != 1 {
+		t.Errorf("node served %d requests with %d failures, want 3 and 1", info.RequestsServed, info.Failures)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		script func(node *Node)
+		status int32
+	}{
+		{
+			name:   "pd rate limited",
+			script: func(node *Node) { node.PD.Fail("/match-history/", http.StatusTooManyRequests, 1) },
+			status: 429,
+		},
+		{
+			name:   "pd down",
+			script: func(node *Node) { node.PD.Fail("/match-details/", http.StatusServiceUnavailable, 1) },
+			status: 500,
+		},
+		{
+			name: "riot client failing",
+			script: func(node *Node) {
+				node.LCU.Fail(http.MethodPost, "/chat/v4/friendrequests", http.StatusInternalServerError, 1)
+			},
+			status: 500,
+		},
+		{
+			name:   "tokens rejected once",
+			script: func(node *Node) { node.PD.Fail("/match-history/", http.StatusUnauthorized, 1) },
+			status: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t, Options{})
+			node := h.AddNode()
+			tt.script(node)
+
+			resp := h.GetAccount(lookup("zcerno", "3137"))
+			if resp.Status != tt.status {
+				t.Errorf("got status %d, want %d: %s", resp.Status, tt.status, resp.Error)
+			}
+			if pending := node.LCU.FriendRequests(); len(pending) != 0 {
+				t.Errorf("node left friend requests behind: %v", pending)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package lcu
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,33 +13,69 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrRateLimited is returned when the riot client answers with 429
+var ErrRateLimited = errors.New("rate limited by riot client")
+
 type Client struct {
 	baseURL    string
 	authHeader string
 	httpClient *http.Client
 	logger     *zap.SugaredLogger
+	tokens     entitlementsCache
+	events     *EventClient
 }
 
-func NewClient(lockfile *LockfileData, logger *zap.SugaredLogger) *Client {
+// NewTransport is how the LCU is reached by default, the riot client serves a
+// self-signed certificate
+func NewTransport() http.RoundTripper {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true, // no need
+		},
+	}
+}
+
+// NewClient creates an LCU client, requests go through transport or through
+// NewTransport when it is nil
+func NewClient(lockfile *LockfileData, transport http.RoundTripper, logger *zap.SugaredLogger) *Client {
+	if transport == nil {
+		transport = NewTransport()
+	}
+
 	return &Client{
 		baseURL:    lockfile.GetBaseURL(),
 		authHeader: lockfile.GetAuthHeader(),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true, // no need
-				},
-			},
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
 		logger: logger,
 	}
 }
 
-func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response, error) {
+// ListenEvents opens the riot client's event socket so resolves can wait for
+// friend request events instead of polling
+func (c *Client) ListenEvents(lockfile *LockfileData) error {
+	events, err := NewEventClient(lockfile, c.logger)
+	if err != nil {
+		return err
+	}
+	events.Start()
+	c.events = events
+	return nil
+}
+
+// Close stops the event socket, the client can still make requests
+func (c *Client) Close() {
+	if c.events != nil {
+		c.events.Close()
+	}
+}
+
+func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
 	url := c.baseURL + path
 
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -58,30 +96,32 @@ func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response,
 }
 
 func (c *Client) get(path string, result interface{}) error {
-	resp, err := c.doRequest("GET", path, nil)
+	return c.getContext(context.Background(), path, result)
+}
+
+func (c *Client) getContext(ctx context.Context, path string, result interface{}) error {
+	resp, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		return statusError(resp)
 	}
 
 	return json.NewDecoder(resp.Body).Decode(result)
 }
 
 func (c *Client) post(path string, body io.Reader, result interface{}) error {
-	resp, err := c.doRequest("POST", path, body)
+	resp, err := c.doRequest(context.Background(), "POST", path, body)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(bodyBytes))
+		return statusError(resp)
 	}
 
 	if result != nil {
@@ -92,16 +132,33 @@ func (c *Client) post(path string, body io.Reader, result interface{}) error {
 }
 
 func (c *Client) delete(path string, body io.Reader) error {
-	resp, err := c.doRequest("DELETE", path, body)
+	resp, err := c.doRequest(context.Background(), "DELETE", path, body)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(bodyBytes))
+		return statusError(resp)
 	}
 
 	return nil
 }
+
+// StatusError is an answer from the riot client with an unexpected status
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.Code, e.Body)
+}
+
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s", ErrRateLimited, string(body))
+	}
+	return &StatusError{Code: resp.StatusCode, Body: string(body)}
+}
@@ -1,5 +1,22 @@
 package lcu
 
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenRefreshMargin refreshes tokens this long before they expire so a
+	// resolve never starts with a token that runs out halfway
+	tokenRefreshMargin = time.Minute
+	// defaultTokenLifetime is used when the token's expiry can't be decoded
+	defaultTokenLifetime = 5 * time.Minute
+)
+
 type EntitlementsTokenResponse struct {
 	AccessToken  string   `json:"accessToken"`
 	Entitlements []string `json:"entitlements"`
@@ -8,11 +25,96 @@ type EntitlementsTokenResponse struct {
 	Token        string   `json:"token"`
 }
 
+// entitlementsCache holds the token pair shared by all resolves of a client
+type entitlementsCache struct {
+	mu      sync.Mutex
+	token   *EntitlementsTokenResponse
+	expires time.Time
+}
+
 func (c *Client) GetEntitlementsToken() (*EntitlementsTokenResponse, error) {
+	return c.entitlementsToken(context.Background())
+}
+
+func (c *Client) entitlementsToken(ctx context.Context) (*EntitlementsTokenResponse, error) {
 	var result EntitlementsTokenResponse
-	err := c.get("/entitlements/v1/token", &result)
+	err := c.getContext(ctx, "/entitlements/v1/token", &result)
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
+
+// Entitlements returns the cached token pair, fetching a new one from the
+// riot client when there is none or it is about to expire. concurrent
+// callers wait for a single fetch
+func (c *Client) Entitlements() (*EntitlementsTokenResponse, error) {
+	return c.entitlements(context.Background())
+}
+
+func (c *Client) entitlements(ctx context.Context) (*EntitlementsTokenResponse, error) {
+	c.tokens.mu.Lock()
+	defer c.tokens.mu.Unlock()
+
+	if c.tokens.token != nil && time.Now().Add(tokenRefreshMargin).Before(c.tokens.expires) {
+		return c.tokens.token, nil
+	}
+
+	token, err := c.entitlementsToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.tokens.token = token
+	c.tokens.expires = tokenExpiry(token, time.Now())
+	c.logger.Debugw("entitlements token refreshed", "expires", c.tokens.expires)
+
+	return token, nil
+}
+
+// InvalidateEntitlements drops the cached token pair if it is still the one
+// that was rejected, so resolves that hit the same 401 refresh only once
+func (c *Client) InvalidateEntitlements(rejected *EntitlementsTokenResponse) {
+	c.tokens.mu.Lock()
+	defer c.tokens.mu.Unlock()
+
+	if c.tokens.token == rejected {
+		c.tokens.token = nil
+	}
+}
+
+// tokenExpiry is when the first of the access and entitlements tokens
+// expires, read from the exp claim of the JWTs
+func tokenExpiry(token *EntitlementsTokenResponse, now time.Time) time.Time {
+	var expires time.Time
+	for _, jwt := range []string{token.AccessToken, token.Token} {
+		exp, ok := jwtExpiry(jwt)
+		if ok && (expires.IsZero() || exp.Before(expires)) {
+			expires = exp
+		}
+	}
+	if expires.IsZero() {
+		return now.Add(defaultTokenLifetime)
+	}
+	return expires
+}
+
+func jwtExpiry(jwt string) (time.Time, bool) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,86 @@
+package lcu
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testJWT(exp time.Time) string {
+	payload, _ := json.Marshal(map[string]int64{"exp": exp.Unix()})
+	return "header." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+}
+
+func TestEntitlementsCache(t *testing.T) {
+	var fetches atomic.Int32
+	var lifetime atomic.Int64
+	lifetime.Store(int64(time.Hour))
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := fetches.Add(1)
+		exp := time.Now().Add(time.Duration(lifetime.Load()))
+		json.NewEncoder(w).Encode(EntitlementsTokenResponse{
+			AccessToken: testJWT(exp),
+			Token:       testJWT(exp),
+			Subject:     fmt.Sprintf("fetch-%d", n),
+		})
+	}))
+	defer server.Close()
+	client := newTestClient(t, server)
+
+	// concurrent resolves share one fetch
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Entitlements(); err != nil {
+				t.Errorf("failed to get entitlements: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetched %d times, want 1", n)
+	}
+
+	// a rejected token is fetched again, but only once
+	rejected, _ := client.Entitlements()
+	client.InvalidateEntitlements(rejected)
+	fresh, err := client.Entitlements()
+	if err != nil || fresh == rejected {
+		t.Fatalf("token not refreshed after invalidation, err = %v", err)
+	}
+	client.InvalidateEntitlements(rejected)
+	if again, _ := client.Entitlements(); again != fresh {
+		t.Error("invalidating an old token dropped the fresh one")
+	}
+
+	// tokens about to expire are refreshed before use
+	lifetime.Store(int64(tokenRefreshMargin / 2))
+	client.InvalidateEntitlements(fresh)
+	expiring, _ := client.Entitlements()
+	if next, _ := client.Entitlements(); next == expiring {
+		t.Error("token inside the refresh margin was reused")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	token := &EntitlementsTokenResponse{AccessToken: testJWT(now.Add(time.Hour)), Token: testJWT(now.Add(30 * time.Minute))}
+	if got := tokenExpiry(token, now); !got.Equal(now.Add(30 * time.Minute)) {
+		t.Errorf("expiry = %s, want the earlier of both tokens", got)
+	}
+
+	token = &EntitlementsTokenResponse{AccessToken: "not a jwt", Token: ""}
+	if got := tokenExpiry(token, now); !got.Equal(now.Add(defaultTokenLifetime)) {
+		t.Errorf("expiry of undecodable tokens = %s, want the default lifetime", got)
+	}
+}
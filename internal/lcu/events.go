@@ -0,0 +1,203 @@
+package lcu
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// friendRequestsEvent is the WAMP topic the riot client publishes
+	// friend request changes on
+	friendRequestsEvent = "OnJsonApiEvent_chat_v4_friendrequests"
+
+	wampSubscribe = 5
+	wampEvent     = 8
+
+	eventReconnectDelay = 5 * time.Second
+)
+
+// EventClient keeps a WAMP websocket to the riot client open and hands
+// friend request updates to whoever is subscribed. it reconnects until closed
+type EventClient struct {
+	config *websocket.Config
+	logger *zap.SugaredLogger
+
+	mu          sync.Mutex
+	conn        *websocket.Conn
+	subscribers map[chan FriendRequest]struct{}
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// jsonAPIEvent is the payload of an OnJsonApiEvent message
+type jsonAPIEvent struct {
+	Data      json.RawMessage `json:"data"`
+	EventType string          `json:"eventType"`
+	URI       string          `json:"uri"`
+}
+
+func NewEventClient(lockfile *LockfileData, logger *zap.SugaredLogger) (*EventClient, error) {
+	host := net.JoinHostPort("127.0.0.1", lockfile.Port)
+	config, err := websocket.NewConfig("wss://"+host+"/", "https://"+host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create websocket config: %w", err)
+	}
+	config.Protocol = []string{"wamp"}
+	config.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+	config.Header.Set("Authorization", lockfile.GetAuthHeader())
+	config.Dialer = &net.Dialer{Timeout: dialTimeout}
+
+	return &EventClient{
+		config:      config,
+		logger:      logger,
+		subscribers: make(map[chan FriendRequest]struct{}),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+func (e *EventClient) Start() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		for {
+			if err := e.listen(); err != nil {
+				e.logger.Debugw("riot client event socket closed", "error", err)
+			}
+
+			select {
+			case <-e.done:
+				return
+			case <-time.After(eventReconnectDelay):
+			}
+		}
+	}()
+}
+
+func (e *EventClient) Close() {
+	close(e.done)
+
+	e.mu.Lock()
+	if e.conn != nil {
+		e.conn.Close()
+	}
+	e.mu.Unlock()
+
+	e.wg.Wait()
+}
+
+// Connected reports whether events are flowing right now. resolves only wait
+// for events while this is true
+func (e *EventClient) Connected() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.conn != nil
+}
+
+// SubscribeFriendRequests returns friend request updates until unsubscribe is
+// called. updates are dropped rather than blocking the socket when the
+// subscriber falls behind
+func (e *EventClient) SubscribeFriendRequests() (<-chan FriendRequest, func()) {
+	ch := make(chan FriendRequest, 16)
+
+	e.mu.Lock()
+	e.subscribers[ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		delete(e.subscribers, ch)
+		e.mu.Unlock()
+	}
+}
+
+func (e *EventClient) listen() error {
+	conn, err := websocket.DialConfig(e.config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	subscribe, _ := json.Marshal([]any{wampSubscribe, friendRequestsEvent})
+	if err := websocket.Message.Send(conn, string(subscribe)); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	e.mu.Lock()
+	select {
+	case <-e.done:
+		e.mu.Unlock()
+		return nil
+	default:
+	}
+	e.conn = conn
+	e.mu.Unlock()
+
+	e.logger.Debugw("subscribed to riot client events")
+
+	defer func() {
+		e.mu.Lock()
+		e.conn = nil
+		e.mu.Unlock()
+	}()
+
+	for {
+		var message []byte
+		if err := websocket.Message.Receive(conn, &message); err != nil {
+			return err
+		}
+		e.dispatch(message)
+	}
+}
+
+// dispatch decodes [8, topic, event] messages. the riot client sends either
+// the whole friend request list or a single request as data
+func (e *EventClient) dispatch(message []byte) {
+	var frame []json.RawMessage
+	if err := json.Unmarshal(message, &frame); err != nil || len(frame) != 3 {
+		return
+	}
+
+	var kind int
+	var topic string
+	if json.Unmarshal(frame[0], &kind) != nil || kind != wampEvent {
+		return
+	}
+	if json.Unmarshal(frame[1], &topic) != nil || topic != friendRequestsEvent {
+		return
+	}
+
+	var event jsonAPIEvent
+	if err := json.Unmarshal(frame[2], &event); err != nil || strings.EqualFold(event.EventType, "Delete") {
+		return
+	}
+
+	var requests FriendRequestsResponse
+	if err := json.Unmarshal(event.Data, &requests); err != nil || len(requests.Requests) == 0 {
+		var single FriendRequest
+		if err := json.Unmarshal(event.Data, &single); err != nil || single.PUUID == "" {
+			return
+		}
+		requests.Requests = []FriendRequest{single}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, request := range requests.Requests {
+		for ch := range e.subscribers {
+			select {
+			case ch <- request:
+			default:
+			}
+		}
+	}
+}
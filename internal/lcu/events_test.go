@@ -0,0 +1,101 @@
+package lcu
+
+import (
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+// fakeEventSocket accepts one WAMP subscription and publishes whatever is
+// sent on the returned channel
+func fakeEventSocket(t *testing.T) (*LockfileData, chan<- string, <-chan string) {
+	t.Helper()
+	publish := make(chan string)
+	subscriptions := make(chan string, 1)
+
+	server := httptest.NewTLSServer(websocket.Server{Handler: func(conn *websocket.Conn) {
+		if conn.Request().Header.Get("Authorization") != (&LockfileData{Password: "secret"}).GetAuthHeader() {
+			return
+		}
+		var subscribe string
+		if err := websocket.Message.Receive(conn, &subscribe); err != nil {
+			return
+		}
+		subscriptions <- subscribe
+		for message := range publish {
+			if err := websocket.Message.Send(conn, message); err != nil {
+				return
+			}
+		}
+	}})
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(publish) })
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	return &LockfileData{Port: port, Password: "secret"}, publish, subscriptions
+}
+
+func friendRequestEvent(t *testing.T, eventType string, data any) string {
+	t.Helper()
+	message, err := json.Marshal([]any{wampEvent, friendRequestsEvent, map[string]any{"data": data, "eventType": eventType, "uri": "/chat/v4/friendrequests"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(message)
+}
+
+func TestEventClientFriendRequests(t *testing.T) {
+	lockfile, publish, subscriptions := fakeEventSocket(t)
+
+	events, err := NewEventClient(lockfile, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	events.Start()
+	defer events.Close()
+
+	select {
+	case subscribe := <-subscriptions:
+		if want := `[5,"` + friendRequestsEvent + `"]`; subscribe != want {
+			t.Errorf("subscribe message = %s, want %s", subscribe, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event client never subscribed")
+	}
+	for !events.Connected() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	requests, unsubscribe := events.SubscribeFriendRequests()
+	defer unsubscribe()
+
+	other := FriendRequest{GameName: "Other", GameTag: "EUW", PUUID: "puuid-2", Subscription: "pending_out"}
+	ours := FriendRequest{GameName: "Player", GameTag: "EUW", PUUID: "puuid-1", Region: "eu2", Subscription: "pending_out"}
+	publish <- `[8,"OnJsonApiEvent_something_else",{}]`
+	publish <- friendRequestEvent(t, "Delete", ours)
+	publish <- friendRequestEvent(t, "Create", other)
+	publish <- friendRequestEvent(t, "Update", FriendRequestsResponse{Requests: []FriendRequest{ours}})
+
+	got := awaitFriendRequest(requests, "Player", "EUW", 5*time.Second)
+	if got == nil || got.PUUID != "puuid-1" || got.Region != "eu2" {
+		t.Fatalf("got %+v, want our pending request", got)
+	}
+
+	if awaitFriendRequest(requests, "Player", "EUW", 50*time.Millisecond) != nil {
+		t.Error("got an event nobody published")
+	}
+}
+
+func TestSubscribeWithoutEvents(t *testing.T) {
+	client := NewClient(&LockfileData{Port: "1", Password: "secret"}, nil, zap.NewNop().Sugar())
+	if _, unsubscribe, ok := client.SubscribeFriendRequests(); ok {
+		t.Error("subscribed without an event socket")
+	} else {
+		unsubscribe()
+	}
+}
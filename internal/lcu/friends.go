@@ -3,6 +3,7 @@ package lcu
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 )
 
 type FriendRequest struct {
@@ -17,6 +18,33 @@ type FriendRequest struct {
 	Subscription string `json:"subscription"`
 }
 
+type Friend struct {
+	GameName string `json:"game_name"`
+	GameTag  string `json:"game_tag"`
+	Name     string `json:"name"`
+	Note     string `json:"note"`
+	PID      string `json:"pid"`
+	PUUID    string `json:"puuid"`
+	Region   string `json:"region"`
+}
+
+type FriendsResponse struct {
+	Friends []Friend `json:"friends"`
+}
+
+// region falls back to the chat server in the pid, puuid@eu1.pvp.net
+func (f *Friend) region() string {
+	if f.Region != "" {
+		return f.Region
+	}
+	_, server, ok := strings.Cut(f.PID, "@")
+	if !ok {
+		return ""
+	}
+	region, _, _ := strings.Cut(server, ".")
+	return region
+}
+
 type FriendRequestsResponse struct {
 	Requests []FriendRequest `json:"requests"`
 }
@@ -30,6 +58,16 @@ type RemoveFriendRequestBody struct {
 	PUUID string `json:"puuid"`
 }
 
+// SubscribeFriendRequests returns friend request events while the event socket
+// is connected, ok is false when the caller has to poll instead
+func (c *Client) SubscribeFriendRequests() (events <-chan FriendRequest, unsubscribe func(), ok bool) {
+	if c.events == nil || !c.events.Connected() {
+		return nil, func() {}, false
+	}
+	events, unsubscribe = c.events.SubscribeFriendRequests()
+	return events, unsubscribe, true
+}
+
 func (c *Client) SendFriendRequest(gameName, gameTag string) error {
 	body := SendFriendRequestBody{
 		GameName: gameName,
@@ -44,6 +82,15 @@ func (c *Client) SendFriendRequest(gameName, gameTag string) error {
 	return c.post("/chat/v4/friendrequests", bytes.NewReader(jsonBody), nil)
 }
 
+func (c *Client) GetFriends() (*FriendsResponse, error) {
+	var result FriendsResponse
+	err := c.get("/chat/v4/friends", &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 func (c *Client) GetFriendRequests() (*FriendRequestsResponse, error) {
 	var result FriendRequestsResponse
 	err := c.get("/chat/v3/friendrequests", &result)
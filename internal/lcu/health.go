@@ -0,0 +1,87 @@
+package lcu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type Status string
+
+const (
+	StatusUnavailable Status = "unavailable"
+	StatusLoggedOut   Status = "logged_out"
+	StatusPatching    Status = "patching"
+	StatusRateLimited Status = "rate_limited"
+	StatusReady       Status = "ready"
+)
+
+// Health is what a probe of the riot client found. Detail says why a node
+// isn't ready
+type Health struct {
+	Status      Status
+	Detail      string
+	PUUID       string
+	GameVersion string
+	// FriendRequestBacklog is how many outgoing friend requests were pending
+	// at the last sweep
+	FriendRequestBacklog int
+}
+
+// Health asks the riot client whether it can resolve accounts right now: an
+// account has to be signed in, chat has to be connected for friend requests
+// and valorant must not be patching. ctx bounds the whole probe
+func (c *Client) Health(ctx context.Context) Health {
+	entitlements, err := c.entitlements(ctx)
+	if err != nil {
+		return unhealthy(err, "no entitlements")
+	}
+	if entitlements.Subject == "" || entitlements.AccessToken == "" {
+		return Health{Status: StatusLoggedOut, Detail: "no account signed in"}
+	}
+
+	chat, err := c.chatSession(ctx)
+	if err != nil {
+		return unhealthy(err, "no chat session")
+	}
+	if chat.State != "connected" {
+		return Health{Status: StatusLoggedOut, Detail: fmt.Sprintf("chat is %s", chat.State)}
+	}
+	if chat.PUUID != "" && chat.PUUID != entitlements.Subject {
+		// the cached token may predate a switch of accounts
+		c.InvalidateEntitlements(entitlements)
+		return Health{Status: StatusLoggedOut, Detail: "chat is signed in to another account"}
+	}
+
+	health := Health{Status: StatusReady, PUUID: entitlements.Subject}
+
+	session, err := c.valorantSession(ctx)
+	if err != nil {
+		return unhealthy(err, "no product session")
+	}
+	if session != nil {
+		health.GameVersion = session.Version
+		if strings.Contains(strings.ToLower(session.Phase), "patch") {
+			health.Status = StatusPatching
+			health.Detail = fmt.Sprintf("valorant is %s", session.Phase)
+		}
+	}
+
+	return health
+}
+
+// unhealthy turns a failed probe request into a status. the riot client
+// answers 404 on the account endpoints while nobody is signed in, any other
+// failure, a refused connection included, means it can't be used
+func unhealthy(err error, detail string) Health {
+	if errors.Is(err, ErrRateLimited) {
+		return Health{Status: StatusRateLimited, Detail: err.Error()}
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+		return Health{Status: StatusLoggedOut, Detail: fmt.Sprintf("%s: %v", detail, err)}
+	}
+	return Health{Status: StatusUnavailable, Detail: fmt.Sprintf("%s: %v", detail, err)}
+}
@@ -0,0 +1,158 @@
+package lcu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestHealth(t *testing.T) {
+	ready := map[string]any{
+		"/entitlements/v1/token":                map[string]any{"accessToken": "access", "subject": "puuid-1", "token": "jwt"},
+		"/chat/v1/session":                      map[string]any{"loaded": true, "puuid": "puuid-1", "state": "connected"},
+		"/product-session/v1/external-sessions": map[string]any{"host_app": map[string]any{"productId": "valorant", "version": "release-09.00", "phase": "Idle"}},
+	}
+
+	tests := []struct {
+		name     string
+		override map[string]any
+		status   int
+		want     Status
+	}{
+		{name: "ready", want: StatusReady},
+		{name: "logged out", override: map[string]any{"/entitlements/v1/token": nil}, want: StatusLoggedOut},
+		{name: "chat disconnected", override: map[string]any{"/chat/v1/session": map[string]any{"state": "disconnected"}}, want: StatusLoggedOut},
+		{name: "other account", override: map[string]any{"/chat/v1/session": map[string]any{"puuid": "puuid-2", "state": "connected"}}, want: StatusLoggedOut},
+		{name: "patching", override: map[string]any{"/product-session/v1/external-sessions": map[string]any{"host_app": map[string]any{"productId": "valorant", "phase": "Patching"}}}, want: StatusPatching},
+		{name: "rate limited", status: http.StatusTooManyRequests, want: StatusRateLimited},
+		{name: "riot client failing", status: http.StatusInternalServerError, want: StatusUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				body, ok := ready[r.URL.Path]
+				if override, overridden := tt.override[r.URL.Path]; overridden {
+					body = override
+				}
+				if !ok || body == nil {
+					http.NotFound(w, r)
+					return
+				}
+				json.NewEncoder(w).Encode(body)
+			}))
+			defer server.Close()
+
+			health := newTestClient(t, server).Health(context.Background())
+			if health.Status != tt.want {
+				t.Errorf("status = %s (%s), want %s", health.Status, health.Detail, tt.want)
+			}
+			if tt.want == StatusReady && (health.PUUID != "puuid-1" || health.GameVersion != "release-09.00") {
+				t.Errorf("got puuid %q version %q", health.PUUID, health.GameVersion)
+			}
+		})
+	}
+}
+
+func TestHealthDeadPort(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	client := newTestClient(t, server)
+	// the riot client went away, its port now refuses connections
+	server.Close()
+
+	health := client.Health(context.Background())
+	if health.Status != StatusUnavailable {
+		t.Errorf("status = %s (%s), want %s", health.Status, health.Detail, StatusUnavailable)
+	}
+}
+
+func TestHealthHungRiotClient(t *testing.T) {
+	hung := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hung
+	}))
+	defer server.Close()
+	defer close(hung)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	health := newTestClient(t, server).Health(ctx)
+	if health.Status != StatusUnavailable {
+		t.Errorf("status = %s (%s), want %s", health.Status, health.Detail, StatusUnavailable)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("probe took %v, want it cut off by its context", elapsed)
+	}
+}
+
+func TestHealthCachesEntitlements(t *testing.T) {
+	var tokenRequests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/entitlements/v1/token":
+			tokenRequests.Add(1)
+			json.NewEncoder(w).Encode(map[string]any{"accessToken": "access", "subject": "puuid-1", "token": "jwt"})
+		case "/chat/v1/session":
+			json.NewEncoder(w).Encode(map[string]any{"puuid": "puuid-1", "state": "connected"})
+		default:
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server)
+	for range 3 {
+		if health := client.Health(context.Background()); health.Status != StatusReady {
+			t.Fatalf("status = %s (%s), want %s", health.Status, health.Detail, StatusReady)
+		}
+	}
+	if n := tokenRequests.Load(); n != 1 {
+		t.Errorf("fetched the entitlements token %d times, want 1", n)
+	}
+}
+
+func TestResolverHealthBacksOff(t *testing.T) {
+	resolver := NewResolver(nil, nil, nil, zap.NewNop().Sugar())
+	if health := resolver.Health(context.Background()); health.Status != StatusUnavailable {
+		t.Errorf("status without a riot client = %s, want %s", health.Status, StatusUnavailable)
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	resolver.SetClient(newTestClient(t, server))
+
+	// the friend request is turned down, so the node backs off
+	if _, err := resolver.ResolveAccount("Player", "EUW"); err == nil {
+		t.Fatal("resolve succeeded against a rate limited riot client")
+	}
+	resolver.mu.RLock()
+	until := resolver.rateLimited
+	resolver.mu.RUnlock()
+	if time.Until(until) <= 0 {
+		t.Error("resolver did not back off after a 429")
+	}
+}
+
+func newTestClient(t *testing.T, server *httptest.Server) *Client {
+	t.Helper()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(&LockfileData{Port: u.Port(), Password: "secret"}, nil, zap.NewNop().Sugar())
+}
@@ -0,0 +1,129 @@
+package lcu
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a friend request the resolver sent and hasn't confirmed
+// deleted yet
+type JournalEntry struct {
+	GameName string    `json:"game_name"`
+	GameTag  string    `json:"game_tag"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// Journal remembers the friend requests this node sent, on disk so requests
+// left behind by a crash are still known after a restart. the sweeper only
+// ever deletes requests found in here
+type Journal struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]JournalEntry
+}
+
+// OpenJournal loads the journal at path, an empty path keeps it in memory
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path, entries: make(map[string]JournalEntry)}
+	if path == "" {
+		return j, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read friend request journal: %w", err)
+	}
+
+	var entries []JournalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse friend request journal: %w", err)
+	}
+	for _, entry := range entries {
+		j.entries[journalKey(entry.GameName, entry.GameTag)] = entry
+	}
+
+	return j, nil
+}
+
+func (j *Journal) Add(gameName, gameTag string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries[journalKey(gameName, gameTag)] = JournalEntry{GameName: gameName, GameTag: gameTag, SentAt: time.Now().UTC()}
+	return j.save()
+}
+
+func (j *Journal) Remove(gameName, gameTag string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key := journalKey(gameName, gameTag)
+	if _, ok := j.entries[key]; !ok {
+		return nil
+	}
+	delete(j.entries, key)
+	return j.save()
+}
+
+// Get returns the entry for a riot id, riot ids are matched case-insensitively
+func (j *Journal) Get(gameName, gameTag string) (JournalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.entries[journalKey(gameName, gameTag)]
+	return entry, ok
+}
+
+func (j *Journal) Entries() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]JournalEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// save replaces the file in one rename so a crash never leaves half a journal
+func (j *Journal) save() error {
+	if j.path == "" {
+		return nil
+	}
+
+	entries := make([]JournalEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(j.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create journal directory: %w", err)
+		}
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write friend request journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("failed to write friend request journal: %w", err)
+	}
+	return nil
+}
+
+func journalKey(gameName, gameTag string) string {
+	return strings.ToLower(gameName + "#" + gameTag)
+}
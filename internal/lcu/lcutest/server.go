@@ -0,0 +1,504 @@
+// Package lcutest runs a fake riot client (LCU) over HTTPS for tests and for
+// running nodes without riot installed. it speaks the endpoints the lcu
+// package uses plus the WAMP event socket, and can be scripted to be slow,
+// fail or lose friend requests
+package lcutest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const friendRequestsEvent = "OnJsonApiEvent_chat_v4_friendrequests"
+
+// Player is an account on the fake riot servers
+type Player struct {
+	GameName string `json:"game_name"`
+	GameTag  string `json:"game_tag"`
+	PUUID    string `json:"puuid"`
+	Region   string `json:"region"`
+}
+
+type friendRequest struct {
+	GameName     string `json:"game_name"`
+	GameTag      string `json:"game_tag"`
+	Name         string `json:"name"`
+	Note         string `json:"note"`
+	PID          string `json:"pid"`
+	Platform     string `json:"platform"`
+	PUUID        string `json:"puuid"`
+	Region       string `json:"region"`
+	Subscription string `json:"subscription"`
+}
+
+type failure struct {
+	status    int
+	remaining int
+}
+
+type Server struct {
+	// Account is who is signed in to the fake riot client
+	Account     Player
+	Password    string
+	GameVersion string
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	players   map[string]Player
+	friends   []Player
+	requests  []friendRequest
+	loggedOut bool
+	patching  bool
+	dropSent  bool
+	delays    map[string]time.Duration
+	failures  map[string]*failure
+	hits      map[string]int
+	sockets   map[*websocket.Conn]bool
+	socketsMu sync.Mutex
+}
+
+// NewServer starts a fake riot client on a random local port, signed in as
+// a generated account
+func NewServer() *Server {
+	s := &Server{
+		Account:     Player{GameName: "Node", GameTag: "0001", PUUID: "00000000-0000-0000-0000-000000000001", Region: "eu1"},
+		Password:    "lcutest",
+		GameVersion: "release-09.00-shipping-1-000000",
+		players:     make(map[string]Player),
+		delays:      make(map[string]time.Duration),
+		failures:    make(map[string]*failure),
+		hits:        make(map[string]int),
+		sockets:     make(map[*websocket.Conn]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /entitlements/v1/token", s.handleEntitlements)
+	mux.HandleFunc("GET /chat/v1/session", s.handleChatSession)
+	mux.HandleFunc("GET /chat/v4/friends", s.handleFriends)
+	mux.HandleFunc("GET /chat/v3/friendrequests", s.handleFriendRequests)
+	mux.HandleFunc("GET /chat/v4/friendrequests", s.handleFriendRequests)
+	mux.HandleFunc("POST /chat/v4/friendrequests", s.handleSendFriendRequest)
+	mux.HandleFunc("DELETE /chat/v4/friendrequests", s.handleDeleteFriendRequest)
+	mux.HandleFunc("GET /player-account/aliases/v1/active", s.handleActiveAlias)
+	mux.HandleFunc("GET /product-session/v1/external-sessions", s.handleSessions)
+
+	events := websocket.Server{Handler: s.handleEvents}
+	s.server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != s.authHeader() {
+			http.Error(w, `{"errorCode":"RPC_ERROR","message":"Invalid URI format"}`, http.StatusUnauthorized)
+			return
+		}
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			events.ServeHTTP(w, r)
+			return
+		}
+		if s.script(w, r) {
+			mux.ServeHTTP(w, r)
+		}
+	}))
+	// the node checks the port with bare tcp connects, which would log
+	// handshake errors
+	s.server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	s.server.StartTLS()
+
+	return s
+}
+
+func (s *Server) Close() {
+	s.socketsMu.Lock()
+	for conn := range s.sockets {
+		conn.Close()
+	}
+	s.socketsMu.Unlock()
+	s.server.Close()
+}
+
+// Port is what the lockfile points at
+func (s *Server) Port() string {
+	_, port, _ := net.SplitHostPort(s.server.Listener.Addr().String())
+	return port
+}
+
+// Lockfile is the line the riot client writes to its lockfile
+func (s *Server) Lockfile() string {
+	return fmt.Sprintf("Riot Client:%d:%s:%s:https", os.Getpid(), s.Port(), s.Password)
+}
+
+// WriteLockfile writes a lockfile pointing at the server to path
+func (s *Server) WriteLockfile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(s.Lockfile()), 0644)
+}
+
+// AddPlayer makes an account known, so friend requests to it show up
+func (s *Server) AddPlayer(p Player) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.players[riotID(p.GameName, p.GameTag)] = p
+}
+
+// AddFriend makes an account a friend of the signed in account
+func (s *Server) AddFriend(p Player) {
+	s.AddPlayer(p)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.friends = append(s.friends, p)
+}
+
+// AddFriendRequest adds a pending request, subscription is pending_in or
+// pending_out
+func (s *Server) AddFriendRequest(p Player, subscription string) {
+	s.AddPlayer(p)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, newFriendRequest(p, subscription))
+}
+
+// FriendRequests returns the pending requests by puuid and subscription
+func (s *Server) FriendRequests() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make(map[string]string, len(s.requests))
+	for _, req := range s.requests {
+		requests[req.PUUID] = req.Subscription
+	}
+	return requests
+}
+
+// SetLoggedOut signs the account out, account endpoints answer 404
+func (s *Server) SetLoggedOut(loggedOut bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loggedOut = loggedOut
+}
+
+// SetPatching puts the valorant session into the patching phase
+func (s *Server) SetPatching(patching bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patching = patching
+}
+
+// DropFriendRequests accepts sent friend requests without them ever showing
+// up, like riot does for some accounts
+func (s *Server) DropFriendRequests(drop bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropSent = drop
+}
+
+// SetDelay delays every answer on path, 0 removes the delay
+func (s *Server) SetDelay(path string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delays[path] = delay
+}
+
+// Fail answers the next times requests of method on path with status,
+// times <= 0 fails until ClearFailures
+func (s *Server) Fail(method, path string, status, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method+" "+path] = &failure{status: status, remaining: times}
+}
+
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = make(map[string]*failure)
+}
+
+// Hits counts the requests of method on path so far
+func (s *Server) Hits(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[method+" "+path]
+}
+
+// script applies delays and failures, false means the request was answered
+func (s *Server) script(w http.ResponseWriter, r *http.Request) bool {
+	route := r.Method + " " + r.URL.Path
+
+	s.mu.Lock()
+	s.hits[route]++
+	delay := s.delays[r.URL.Path]
+	status := 0
+	if f, ok := s.failures[route]; ok {
+		status = f.status
+		if f.remaining > 0 {
+			if f.remaining--; f.remaining == 0 {
+				delete(s.failures, route)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return false
+		}
+	}
+	if status != 0 {
+		http.Error(w, fmt.Sprintf(`{"errorCode":"SCRIPTED","httpStatus":%d}`, status), status)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleEntitlements(w http.ResponseWriter, r *http.Request) {
+	if !s.signedIn(w) {
+		return
+	}
+	expires := time.Now().Add(time.Hour)
+	writeJSON(w, map[string]any{
+		"accessToken":  Token(s.Account.PUUID, expires),
+		"entitlements": []string{},
+		"issuer":       "https://entitlements.auth.riotgames.com",
+		"subject":      s.Account.PUUID,
+		"token":        Token(s.Account.PUUID, expires),
+	})
+}
+
+func (s *Server) handleChatSession(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	loggedOut := s.loggedOut
+	s.mu.Unlock()
+
+	if loggedOut {
+		writeJSON(w, map[string]any{"loaded": true, "state": "disconnected"})
+		return
+	}
+	writeJSON(w, map[string]any{
+		"loaded":    true,
+		"puuid":     s.Account.PUUID,
+		"state":     "connected",
+		"game_name": s.Account.GameName,
+		"game_tag":  s.Account.GameTag,
+		"region":    s.Account.Region,
+	})
+}
+
+func (s *Server) handleFriends(w http.ResponseWriter, r *http.Request) {
+	if !s.signedIn(w) {
+		return
+	}
+	s.mu.Lock()
+	friends := make([]map[string]any, 0, len(s.friends))
+	for _, p := range s.friends {
+		friends = append(friends, map[string]any{
+			"game_name": p.GameName,
+			"game_tag":  p.GameTag,
+			"name":      p.GameName,
+			"pid":       p.PUUID + "@" + p.Region + ".pvp.net",
+			"puuid":     p.PUUID,
+			"region":    p.Region,
+		})
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]any{"friends": friends})
+}
+
+func (s *Server) handleFriendRequests(w http.ResponseWriter, r *http.Request) {
+	if !s.signedIn(w) {
+		return
+	}
+	s.mu.Lock()
+	requests := append([]friendRequest{}, s.requests...)
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]any{"requests": requests})
+}
+
+func (s *Server) handleSendFriendRequest(w http.ResponseWriter, r *http.Request) {
+	if !s.signedIn(w) {
+		return
+	}
+	var body struct {
+		GameName string `json:"game_name"`
+		GameTag  string `json:"game_tag"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"errorCode":"BAD_REQUEST"}`, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	player, known := s.players[riotID(body.GameName, body.GameTag)]
+	for _, friend := range s.friends {
+		if known && friend.PUUID == player.PUUID {
+			s.mu.Unlock()
+			http.Error(w, `{"errorCode":"FRIEND_EXISTS"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	for _, req := range s.requests {
+		if known && req.PUUID == player.PUUID {
+			s.mu.Unlock()
+			http.Error(w, `{"errorCode":"REQUEST_EXISTS"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	// unknown accounts are accepted too, the request just never shows up
+	var created *friendRequest
+	if known && !s.dropSent {
+		req := newFriendRequest(player, "pending_out")
+		s.requests = append(s.requests, req)
+		created = &req
+	}
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+	if created != nil {
+		s.publish("Create", created)
+	}
+}
+
+func (s *Server) handleDeleteFriendRequest(w http.ResponseWriter, r *http.Request) {
+	if !s.signedIn(w) {
+		return
+	}
+	var body struct {
+		PUUID string `json:"puuid"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"errorCode":"BAD_REQUEST"}`, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	var deleted *friendRequest
+	for i, req := range s.requests {
+		if req.PUUID == body.PUUID {
+			deleted = &req
+			s.requests = append(s.requests[:i], s.requests[i+1:]...)
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if deleted == nil {
+		http.Error(w, `{"errorCode":"NOT_FOUND"}`, http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	s.publish("Delete", deleted)
+}
+
+func (s *Server) handleActiveAlias(w http.ResponseWriter, r *http.Request) {
+	if !s.signedIn(w) {
+		return
+	}
+	writeJSON(w, map[string]any{
+		"active":    true,
+		"game_name": s.Account.GameName,
+		"tag_line":  s.Account.GameTag,
+		"puuid":     s.Account.PUUID,
+	})
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	phase := "Idle"
+	if s.patching {
+		phase = "Patching"
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]any{
+		"host_app": map[string]any{"productId": "valorant", "version": s.GameVersion, "phase": phase},
+	})
+}
+
+// handleEvents serves the WAMP socket, subscriptions are accepted and every
+// friend request change is published
+func (s *Server) handleEvents(conn *websocket.Conn) {
+	s.socketsMu.Lock()
+	s.sockets[conn] = true
+	s.socketsMu.Unlock()
+
+	defer func() {
+		s.socketsMu.Lock()
+		delete(s.sockets, conn)
+		s.socketsMu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		var message string
+		if err := websocket.Message.Receive(conn, &message); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) publish(eventType string, req *friendRequest) {
+	message, err := json.Marshal([]any{8, friendRequestsEvent, map[string]any{
+		"data":      map[string]any{"requests": []friendRequest{*req}},
+		"eventType": eventType,
+		"uri":       "/chat/v4/friendrequests",
+	}})
+	if err != nil {
+		return
+	}
+
+	s.socketsMu.Lock()
+	defer s.socketsMu.Unlock()
+	for conn := range s.sockets {
+		websocket.Message.Send(conn, string(message))
+	}
+}
+
+func (s *Server) signedIn(w http.ResponseWriter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loggedOut {
+		http.Error(w, `{"errorCode":"RESOURCE_NOT_FOUND","message":"Not signed in"}`, http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+func (s *Server) authHeader() string {
+	return "Basic " + base64Auth("riot:"+s.Password)
+}
+
+func newFriendRequest(p Player, subscription string) friendRequest {
+	return friendRequest{
+		GameName:     p.GameName,
+		GameTag:      p.GameTag,
+		Name:         p.GameName,
+		PID:          p.PUUID + "@" + p.Region + ".pvp.net",
+		Platform:     "PC",
+		PUUID:        p.PUUID,
+		Region:       p.Region,
+		Subscription: subscription,
+	}
+}
+
+func riotID(gameName, gameTag string) string {
+	return strings.ToLower(gameName + "#" + gameTag)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,19 @@
+package lcutest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Token returns an unsigned JWT for subject that expires at expires, enough
+// for code that only reads the claims
+func Token(subject string, expires time.Time) string {
+	header, _ := json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{"sub": subject, "exp": expires.Unix()})
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims) + ".fake"
+}
+
+func base64Auth(credentials string) string {
+	return base64.StdEncoding.EncodeToString([]byte(credentials))
+}
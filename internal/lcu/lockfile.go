@@ -14,19 +14,136 @@ type LockfileData struct {
 	Port     string
 	Password string
 	Protocol string
+	// Path is where the data was found, a file or the riot client process
+	Path string
 }
 
-func ReadLockfile() (*LockfileData, error) {
-	localAppData := os.Getenv("LOCALAPPDATA")
-	if localAppData == "" {
-		return nil, fmt.Errorf("LOCALAPPDATA environment variable not set")
+// LockfileAttempt is one place FindLockfile looked and why it didn't work
+type LockfileAttempt struct {
+	Path string
+	Err  error
+}
+
+type LockfileNotFoundError struct {
+	Attempts []LockfileAttempt
+}
+
+func (e *LockfileNotFoundError) Error() string {
+	var b strings.Builder
+	b.WriteString("riot client lockfile not found (is Riot Client running?), tried:")
+	for _, attempt := range e.Attempts {
+		fmt.Fprintf(&b, "\n  %s: %v", attempt.Path, attempt.Err)
+	}
+	return b.String()
+}
+
+// FindLockfile looks for the riot client lockfile at explicitPath, then at
+// the known Windows, macOS, Wine and Proton locations, then in the command
+// line of a running riot client. the error lists everything that was tried
+func FindLockfile(explicitPath string) (*LockfileData, error) {
+	lockfile, notFound := findLockfileOnDisk(explicitPath)
+	if lockfile != nil {
+		return lockfile, nil
+	}
+	return notFound.inspect(lockfileFromProcess)
+}
+
+// findLockfileOnDisk tries the lockfile candidates only, the cheap part of
+// FindLockfile
+func findLockfileOnDisk(explicitPath string) (*LockfileData, *LockfileNotFoundError) {
+	var candidates []string
+	if explicitPath != "" {
+		candidates = append(candidates, explicitPath)
+	}
+	candidates = append(candidates, lockfileCandidates(os.Getenv, userHome())...)
+
+	notFound := &LockfileNotFoundError{}
+	seen := make(map[string]bool)
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		lockfile, err := ReadLockfile(path)
+		if err == nil {
+			return lockfile, nil
+		}
+		notFound.Attempts = append(notFound.Attempts, LockfileAttempt{Path: path, Err: err})
+	}
+
+	return nil, notFound
+}
+
+// inspect falls back to finding the riot client among the running processes
+func (e *LockfileNotFoundError) inspect(fromProcess func() (*LockfileData, error)) (*LockfileData, error) {
+	lockfile, err := fromProcess()
+	if err == nil {
+		return lockfile, nil
+	}
+	e.Attempts = append(e.Attempts, LockfileAttempt{Path: "process inspection", Err: err})
+
+	return nil, e
+}
+
+// lockfileCandidates lists where the riot client keeps its lockfile on each
+// platform. wine and proton prefixes are globbed since the prefix and the
+// windows user name differ per install
+func lockfileCandidates(getenv func(string) string, home string) []string {
+	const riotConfig = "Riot Games/Riot Client/Config/lockfile"
+
+	var candidates []string
+	if localAppData := getenv("LOCALAPPDATA"); localAppData != "" {
+		candidates = append(candidates, filepath.Join(localAppData, filepath.FromSlash(riotConfig)))
+	}
+	if home == "" {
+		return candidates
+	}
+
+	// macOS
+	candidates = append(candidates, filepath.Join(home, "Library/Application Support", riotConfig))
+
+	// wine, lutris and proton prefixes on linux
+	var prefixes []string
+	if prefix := getenv("WINEPREFIX"); prefix != "" {
+		prefixes = append(prefixes, prefix)
+	}
+	prefixes = append(prefixes,
+		filepath.Join(home, ".wine"),
+		filepath.Join(home, "Games", "*"),
+		filepath.Join(home, ".local/share/lutris/prefixes", "*"),
+		filepath.Join(home, ".steam/steam/steamapps/compatdata", "*", "pfx"),
+		filepath.Join(home, ".local/share/Steam/steamapps/compatdata", "*", "pfx"),
+	)
+	for _, prefix := range prefixes {
+		pattern := filepath.Join(prefix, "drive_c/users/*/AppData/Local", riotConfig)
+		matches, err := filepath.Glob(pattern)
+		if err != nil || len(matches) == 0 {
+			candidates = append(candidates, pattern)
+			continue
+		}
+		candidates = append(candidates, matches...)
 	}
 
-	lockfilePath := filepath.Join(localAppData, "Riot Games", "Riot Client", "Config", "lockfile")
+	return candidates
+}
 
-	file, err := os.Open(lockfilePath)
+func userHome() string {
+	home, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open lockfile: %w (is Riot Client running?)", err)
+		return ""
+	}
+	return home
+}
+
+// ReadLockfile parses the lockfile at path
+func ReadLockfile(path string) (*LockfileData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no such file")
+		}
+		return nil, fmt.Errorf("failed to open lockfile: %w", err)
 	}
 	defer file.Close()
 
@@ -47,6 +164,7 @@ func ReadLockfile() (*LockfileData, error) {
 		Port:     parts[2],
 		Password: parts[3],
 		Protocol: parts[4],
+		Path:     path,
 	}, nil
 }
 
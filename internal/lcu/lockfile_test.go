@@ -0,0 +1,108 @@
+package lcu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeLockfile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockfileCandidates(t *testing.T) {
+	home := t.TempDir()
+	wine := filepath.Join(home, ".wine", "drive_c", "users", "player", "AppData", "Local", "Riot Games", "Riot Client", "Config", "lockfile")
+	proton := filepath.Join(home, ".steam", "steam", "steamapps", "compatdata", "1234", "pfx", "drive_c", "users", "steamuser", "AppData", "Local", "Riot Games", "Riot Client", "Config", "lockfile")
+	writeLockfile(t, wine, "Riot Client:1:1111:secret:https")
+	writeLockfile(t, proton, "Riot Client:2:2222:secret:https")
+
+	env := map[string]string{"LOCALAPPDATA": filepath.Join(home, "AppData", "Local")}
+	candidates := lockfileCandidates(func(key string) string { return env[key] }, home)
+
+	for _, want := range []string{
+		filepath.Join(home, "AppData", "Local", "Riot Games", "Riot Client", "Config", "lockfile"),
+		filepath.Join(home, "Library", "Application Support", "Riot Games", "Riot Client", "Config", "lockfile"),
+		wine,
+		proton,
+	} {
+		found := false
+		for _, candidate := range candidates {
+			if candidate == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("candidates missing %s", want)
+		}
+	}
+}
+
+func TestReadLockfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lockfile")
+	writeLockfile(t, path, "Riot Client:1234:56789:p4ss:https")
+
+	lockfile, err := ReadLockfile(path)
+	if err != nil {
+		t.Fatalf("failed to read lockfile: %v", err)
+	}
+	if lockfile.PID != "1234" || lockfile.Port != "56789" || lockfile.Password != "p4ss" || lockfile.Path != path {
+		t.Errorf("got %+v", lockfile)
+	}
+
+	writeLockfile(t, path, "garbage")
+	if _, err := ReadLockfile(path); err == nil {
+		t.Error("read a malformed lockfile")
+	}
+}
+
+func TestFindLockfileExplicitPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom", "lockfile")
+	writeLockfile(t, path, "Riot Client:1:4242:secret:https")
+
+	lockfile, err := FindLockfile(path)
+	if err != nil {
+		t.Fatalf("failed to find lockfile: %v", err)
+	}
+	if lockfile.Port != "4242" {
+		t.Errorf("port = %s, want 4242", lockfile.Port)
+	}
+}
+
+func TestLockfileNotFoundError(t *testing.T) {
+	err := error(&LockfileNotFoundError{Attempts: []LockfileAttempt{
+		{Path: "/a/lockfile", Err: errors.New("no such file")},
+		{Path: "process inspection", Err: errors.New("no riot client process")},
+	}})
+
+	for _, want := range []string{"/a/lockfile: no such file", "process inspection: no riot client process"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestParseRiotCommandLine(t *testing.T) {
+	lockfile := parseRiotCommandLine(process{
+		pid:     "321",
+		cmdline: `C:\Riot Games\Riot Client\RiotClientServices.exe --app-port=50123 --remoting-auth-token=abc-DEF_123 --launch-product=valorant`,
+	})
+	if lockfile == nil {
+		t.Fatal("riot client command line not recognized")
+	}
+	if lockfile.Port != "50123" || lockfile.Password != "abc-DEF_123" || lockfile.PID != "321" {
+		t.Errorf("got %+v", lockfile)
+	}
+
+	if parseRiotCommandLine(process{pid: "1", cmdline: "/usr/bin/other --app-port=1 --remoting-auth-token=x"}) != nil {
+		t.Error("matched a process that isn't the riot client")
+	}
+}
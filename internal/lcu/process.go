@@ -0,0 +1,123 @@
+package lcu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+var (
+	appPortArg   = regexp.MustCompile(`--app-port=(\d+)`)
+	authTokenArg = regexp.MustCompile(`--remoting-auth-token=([\w-]+)`)
+)
+
+type process struct {
+	pid     string
+	cmdline string
+}
+
+// lockfileFromProcess rebuilds the lockfile from the riot client command
+// line, which carries the same port and password
+func lockfileFromProcess() (*LockfileData, error) {
+	processes, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range processes {
+		if lockfile := parseRiotCommandLine(p); lockfile != nil {
+			return lockfile, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no riot client process with --app-port and --remoting-auth-token")
+}
+
+func parseRiotCommandLine(p process) *LockfileData {
+	if !strings.Contains(p.cmdline, "Riot") {
+		return nil
+	}
+
+	port := appPortArg.FindStringSubmatch(p.cmdline)
+	token := authTokenArg.FindStringSubmatch(p.cmdline)
+	if port == nil || token == nil {
+		return nil
+	}
+
+	return &LockfileData{
+		Name:     "Riot Client",
+		PID:      p.pid,
+		Port:     port[1],
+		Password: token[1],
+		Protocol: "https",
+		Path:     "process " + p.pid,
+	}
+}
+
+// fromProcess reports whether the lockfile was rebuilt from a command line
+func (l *LockfileData) fromProcess() bool {
+	return strings.HasPrefix(l.Path, "process ")
+}
+
+func listProcesses() ([]process, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return listProcProcesses()
+	case "windows":
+		return listCommandProcesses("powershell", "-NoProfile", "-Command",
+			`Get-CimInstance Win32_Process -Filter "Name like 'Riot%'" | ForEach-Object { "$($_.ProcessId) $($_.CommandLine)" }`)
+	default:
+		return listCommandProcesses("ps", "-axww", "-o", "pid=,args=")
+	}
+}
+
+// listProcProcesses reads /proc, which also shows riot clients running
+// under wine or proton with their windows command line
+func listProcProcesses() ([]process, error) {
+	paths, err := filepath.Glob("/proc/[0-9]*/cmdline")
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []process
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		processes = append(processes, process{
+			pid:     filepath.Base(filepath.Dir(path)),
+			cmdline: strings.ReplaceAll(string(data), "\x00", " "),
+		})
+	}
+
+	return processes, nil
+}
+
+// listCommandProcesses parses "pid command line" output
+func listCommandProcesses(name string, args ...string) ([]process, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes with %s: %w", name, err)
+	}
+
+	var processes []process
+	for _, line := range strings.Split(string(out), "\n") {
+		pid, cmdline, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		processes = append(processes, process{pid: pid, cmdline: cmdline})
+	}
+
+	return processes, nil
+}
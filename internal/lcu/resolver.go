@@ -1,7 +1,11 @@
 package lcu
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -9,6 +13,23 @@ import (
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/valorant"
 )
 
+var (
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	ErrNoMatchHistory        = errors.New("no match history found for player")
+	ErrRiotClientUnavailable = errors.New("riot client is not available")
+)
+
+const (
+	// rateLimitBackoff is how long a node reports itself rate limited after
+	// riot turned down one of its requests
+	rateLimitBackoff = 2 * time.Minute
+	// friendRequestEventTimeout is how long a resolve waits for the friend
+	// request event before it starts polling
+	friendRequestEventTimeout = 3 * time.Second
+	friendRequestPollInterval = 500 * time.Millisecond
+	friendRequestPollAttempts = 10
+)
+
 type AccountData struct {
 	PUUID        string
 	Region       string
@@ -20,84 +41,107 @@ type AccountData struct {
 }
 
 type Resolver struct {
-	lcuClient *Client
-	valClient *valorant.Client
-	logger    *zap.SugaredLogger
+	mu          sync.RWMutex
+	lcuClient   *Client
+	rateLimited time.Time
+	backlog     int
+	journal     *Journal
+	// eventTimeout and pollInterval pace the wait for a sent friend request
+	eventTimeout time.Duration
+	pollInterval time.Duration
+	valClient    *valorant.Client
+	logger       *zap.SugaredLogger
 }
 
-func NewResolver(lcuClient *Client, valClient *valorant.Client, logger *zap.SugaredLogger) *Resolver {
+// NewResolver creates a resolver, lcuClient may be nil until a Watcher finds
+// the riot client. sent friend requests are recorded in journal, if set, so a
+// Sweeper can clean up the ones that were never deleted
+func NewResolver(lcuClient *Client, valClient *valorant.Client, journal *Journal, logger *zap.SugaredLogger) *Resolver {
 	return &Resolver{
 		lcuClient: lcuClient,
 		valClient: valClient,
+		journal:   journal,
 		logger:    logger,
+
+		eventTimeout: friendRequestEventTimeout,
+		pollInterval: friendRequestPollInterval,
 	}
 }
 
 func (r *Resolver) ResolveAccount(gameName, gameTag string) (*AccountData, error) {
+	account, err := r.resolveAccount(gameName, gameTag)
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, valorant.ErrRateLimited) {
+		r.mu.Lock()
+		r.rateLimited = time.Now().Add(rateLimitBackoff)
+		r.mu.Unlock()
+	}
+	return account, err
+}
+
+func (r *Resolver) resolveAccount(gameName, gameTag string) (*AccountData, error) {
 	r.logger.Infow("resolving account", "name", gameName, "tag", gameTag)
 
-	entitlements, err := r.lcuClient.GetEntitlementsToken()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get entitlements token: %w", err)
+	// a resolve sticks with the client it started with even if the riot
+	// client restarts halfway
+	lcuClient := r.client()
+	if lcuClient == nil {
+		return nil, ErrRiotClientUnavailable
 	}
 
-	err = r.lcuClient.SendFriendRequest(gameName, gameTag)
+	entitlements, err := lcuClient.Entitlements()
 	if err != nil {
-		return nil, fmt.Errorf("failed to send friend request: %w", err)
+		return nil, fmt.Errorf("failed to get entitlements token: %w", err)
 	}
 
-	var friendReq *FriendRequest
-	maxAttempts := 10
-	for i := 0; i < maxAttempts; i++ {
-		time.Sleep(500 * time.Millisecond)
-
-		requests, err := r.lcuClient.GetFriendRequests()
+	// friends and requests the account already has are used as they are, a
+	// friend request to them would fail and they must never be deleted
+	friendReq, ours := r.findContact(lcuClient, gameName, gameTag)
+	if friendReq == nil {
+		friendReq, err = r.sendFriendRequest(lcuClient, gameName, gameTag)
 		if err != nil {
-			r.logger.Warn("failed to get friend requests", "error", err)
-			continue
+			return nil, err
 		}
-
-		for j := range requests.Requests {
-			req := &requests.Requests[j]
-			if req.GameName == gameName && req.GameTag == gameTag && req.Subscription == "pending_out" {
-				friendReq = req
-				break
-			}
-		}
-
-		if friendReq != nil {
-			break
-		}
-	}
-
-	if friendReq == nil {
-		return nil, fmt.Errorf("friend request not found after %d attempts", maxAttempts)
+		ours = true
 	}
 
-	r.logger.Debugw("found friend request", "puuid", friendReq.PUUID, "region", friendReq.Region)
+	r.logger.Debugw("found friend request", "puuid", friendReq.PUUID, "region", friendReq.Region, "sent", ours)
 
-	defer func() {
-		err := r.lcuClient.DeleteFriendRequest(friendReq.PUUID)
-		if err != nil {
-			r.logger.Warnw("failed to delete friend request", "puuid", friendReq.PUUID, "error", err)
-		}
-	}()
+	if ours {
+		defer func() {
+			err := lcuClient.DeleteFriendRequest(friendReq.PUUID)
+			if err != nil {
+				r.logger.Warnw("failed to delete friend request, leaving it to the sweeper", "puuid", friendReq.PUUID, "error", err)
+				return
+			}
+			if r.journal != nil {
+				r.forgetFriendRequest(gameName, gameTag)
+			}
+		}()
+	}
 
 	shard := valorant.RegionToShard(friendReq.Region)
 	r.logger.Debugw("mapped region to shard", "region", friendReq.Region, "shard", shard)
 
-	matchHistory, err := r.valClient.GetMatchHistory(shard, friendReq.PUUID, entitlements.AccessToken, entitlements.Token)
+	var matchHistory *valorant.MatchHistoryResponse
+	err = r.withEntitlements(lcuClient, &entitlements, func(tokens *EntitlementsTokenResponse) (err error) {
+		matchHistory, err = r.valClient.GetMatchHistory(shard, friendReq.PUUID, tokens.AccessToken, tokens.Token)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get match history: %w", err)
 	}
 
 	if len(matchHistory.History) == 0 {
-		return nil, fmt.Errorf("no match history found for player")
+		return nil, ErrNoMatchHistory
 	}
 
 	matchID := matchHistory.History[0].MatchID
 	r.logger.Debugw("fetching match details", "matchID", matchID)
-	matchDetails, err := r.valClient.GetMatchDetails(shard, matchID, entitlements.AccessToken, entitlements.Token)
+	var matchDetails *valorant.MatchDetailsResponse
+	err = r.withEntitlements(lcuClient, &entitlements, func(tokens *EntitlementsTokenResponse) (err error) {
+		matchDetails, err = r.valClient.GetMatchDetails(shard, matchID, tokens.AccessToken, tokens.Token)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get match details: %w", err)
 	}
@@ -119,3 +163,180 @@ func (r *Resolver) ResolveAccount(gameName, gameTag string) (*AccountData, error
 		Title:        player.PlayerTitle,
 	}, nil
 }
+
+// findContact looks for the account among the node's friends and pending
+// requests. ours is true only for an outgoing request the journal says this
+// node sent, anything else belongs to the account's owner
+func (r *Resolver) findContact(lcuClient *Client, gameName, gameTag string) (contact *FriendRequest, ours bool) {
+	friends, err := lcuClient.GetFriends()
+	if err != nil {
+		r.logger.Warnw("failed to get friends", "error", err)
+	} else {
+		for _, friend := range friends.Friends {
+			if strings.EqualFold(friend.GameName, gameName) && strings.EqualFold(friend.GameTag, gameTag) {
+				r.logger.Debugw("account is a friend of the node", "puuid", friend.PUUID)
+				return &FriendRequest{GameName: friend.GameName, GameTag: friend.GameTag, PUUID: friend.PUUID, Region: friend.region()}, false
+			}
+		}
+	}
+
+	requests, err := lcuClient.GetFriendRequests()
+	if err != nil {
+		r.logger.Warnw("failed to get friend requests", "error", err)
+		return nil, false
+	}
+	for i := range requests.Requests {
+		req := &requests.Requests[i]
+		if !strings.EqualFold(req.GameName, gameName) || !strings.EqualFold(req.GameTag, gameTag) {
+			continue
+		}
+		switch req.Subscription {
+		case "pending_in":
+			return req, false
+		case "pending_out":
+			journaled := false
+			if r.journal != nil {
+				_, journaled = r.journal.Get(req.GameName, req.GameTag)
+			}
+			return req, journaled
+		}
+	}
+
+	return nil, false
+}
+
+// sendFriendRequest sends a friend request and waits for it to show up, which
+// is when riot tells us the puuid and region
+func (r *Resolver) sendFriendRequest(lcuClient *Client, gameName, gameTag string) (*FriendRequest, error) {
+	// subscribe before sending so the event can't be missed
+	events, unsubscribe, listening := lcuClient.SubscribeFriendRequests()
+	defer unsubscribe()
+
+	if r.journal != nil {
+		if err := r.journal.Add(gameName, gameTag); err != nil {
+			r.logger.Warnw("failed to journal friend request", "error", err)
+		}
+	}
+
+	if err := lcuClient.SendFriendRequest(gameName, gameTag); err != nil {
+		return nil, fmt.Errorf("failed to send friend request: %w", err)
+	}
+
+	var friendReq *FriendRequest
+	if listening {
+		friendReq = awaitFriendRequest(events, gameName, gameTag, r.eventTimeout)
+		if friendReq == nil {
+			r.logger.Debugw("no friend request event, polling", "name", gameName, "tag", gameTag)
+		}
+	}
+	if friendReq == nil {
+		friendReq = r.pollFriendRequest(lcuClient, gameName, gameTag)
+	}
+
+	if friendReq == nil {
+		return nil, fmt.Errorf("%w after %d attempts", ErrFriendRequestNotFound, friendRequestPollAttempts)
+	}
+	return friendReq, nil
+}
+
+// awaitFriendRequest waits for the event of our outgoing request
+func awaitFriendRequest(events <-chan FriendRequest, gameName, gameTag string, timeout time.Duration) *FriendRequest {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case req := <-events:
+			if isOutgoingRequest(&req, gameName, gameTag) {
+				return &req
+			}
+		case <-timer.C:
+			return nil
+		}
+	}
+}
+
+func (r *Resolver) pollFriendRequest(lcuClient *Client, gameName, gameTag string) *FriendRequest {
+	for i := 0; i < friendRequestPollAttempts; i++ {
+		time.Sleep(r.pollInterval)
+
+		requests, err := lcuClient.GetFriendRequests()
+		if err != nil {
+			r.logger.Warn("failed to get friend requests", "error", err)
+			continue
+		}
+
+		for j := range requests.Requests {
+			req := &requests.Requests[j]
+			if isOutgoingRequest(req, gameName, gameTag) {
+				return req
+			}
+		}
+	}
+	return nil
+}
+
+// isOutgoingRequest matches riot ids case-insensitively, riot answers with the
+// account's own casing whatever the lookup used
+func isOutgoingRequest(req *FriendRequest, gameName, gameTag string) bool {
+	return strings.EqualFold(req.GameName, gameName) && strings.EqualFold(req.GameTag, gameTag) && req.Subscription == "pending_out"
+}
+
+// withEntitlements runs a riot api request with the cached tokens. when riot
+// rejects them they are refreshed and the request is retried once
+func (r *Resolver) withEntitlements(lcuClient *Client, tokens **EntitlementsTokenResponse, request func(tokens *EntitlementsTokenResponse) error) error {
+	err := request(*tokens)
+	if !errors.Is(err, valorant.ErrUnauthorized) {
+		return err
+	}
+
+	r.logger.Infow("riot api rejected the entitlements token, refreshing")
+	lcuClient.InvalidateEntitlements(*tokens)
+
+	fresh, err := lcuClient.Entitlements()
+	if err != nil {
+		return fmt.Errorf("failed to refresh entitlements token: %w", err)
+	}
+	*tokens = fresh
+
+	return request(fresh)
+}
+
+// Health probes the riot client, a node that was rate limited recently stays
+// rate limited until the backoff ran out
+func (r *Resolver) Health(ctx context.Context) Health {
+	r.mu.RLock()
+	lcuClient, rateLimited, backlog := r.lcuClient, r.rateLimited, r.backlog
+	r.mu.RUnlock()
+
+	if lcuClient == nil {
+		return Health{Status: StatusUnavailable, Detail: ErrRiotClientUnavailable.Error()}
+	}
+
+	health := lcuClient.Health(ctx)
+	health.FriendRequestBacklog = backlog
+	if health.Status == StatusReady && time.Now().Before(rateLimited) {
+		health.Status = StatusRateLimited
+		health.Detail = fmt.Sprintf("backing off until %s", rateLimited.Format(time.RFC3339))
+	}
+	return health
+}
+
+// SetClient swaps the LCU client used by new resolves, nil marks the riot
+// client as gone
+func (r *Resolver) SetClient(lcuClient *Client) {
+	r.mu.Lock()
+	r.lcuClient = lcuClient
+	r.mu.Unlock()
+}
+
+// Available reports whether the resolver has a riot client to work with
+func (r *Resolver) Available() bool {
+	return r.client() != nil
+}
+
+func (r *Resolver) client() *Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lcuClient
+}
@@ -0,0 +1,325 @@
+package lcu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/cassette"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/lcu/lcutest"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/valorant"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/valorant/pdtest"
+)
+
+var (
+	friend   = lcutest.Player{GameName: "Friend", GameTag: "EUW", PUUID: "puuid-friend", Region: "eu1"}
+	incoming = lcutest.Player{GameName: "Incoming", GameTag: "EUW", PUUID: "puuid-incoming", Region: "eu2"}
+	manual   = lcutest.Player{GameName: "Manual", GameTag: "EUW", PUUID: "puuid-manual", Region: "eu2"}
+	orphan   = lcutest.Player{GameName: "Orphan", GameTag: "EUW", PUUID: "puuid-orphan", Region: "eu2"}
+	stranger = lcutest.Player{GameName: "Stranger", GameTag: "EUW", PUUID: "puuid-stranger", Region: "na1"}
+
+	// players with pd fixtures
+	zcerno    = lcutest.Player{GameName: "zcerno", GameTag: "3137", PUUID: "e83573ec-ec6f-5034-9a38-ed0ccf8dbb1b", Region: "eu2"}
+	noMatches = lcutest.Player{GameName: "abcd", GameTag: "1234", PUUID: "0f5c7a5e-3b4d-4c8e-9a21-6d2f1b7c8e90", Region: "na1"}
+)
+
+// newFakeResolver starts a fake riot client and a resolver attached to it
+// the way the node attaches it, through a lockfile and the watcher
+func newFakeResolver(t *testing.T) (*lcutest.Server, *Resolver) {
+	t.Helper()
+	fake := lcutest.NewServer()
+	t.Cleanup(fake.Close)
+
+	path := filepath.Join(t.TempDir(), "lockfile")
+	if err := fake.WriteLockfile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	journal, _ := OpenJournal("")
+	resolver := NewResolver(nil, nil, journal, zap.NewNop().Sugar())
+	resolver.pollInterval = 10 * time.Millisecond
+
+	watcher := NewWatcher(path, time.Hour, resolver, zap.NewNop().Sugar())
+	watcher.check()
+	t.Cleanup(watcher.Stop)
+
+	if !resolver.Available() {
+		t.Fatal("resolver not attached to the fake riot client")
+	}
+	return fake, resolver
+}
+
+func TestFindContact(t *testing.T) {
+	fake, resolver := newFakeResolver(t)
+	fake.AddFriend(friend)
+	fake.AddFriendRequest(incoming, "pending_in")
+	fake.AddFriendRequest(manual, "pending_out")
+	fake.AddFriendRequest(orphan, "pending_out")
+	resolver.journal.Add("Orphan", "EUW")
+
+	tests := []struct {
+		name, tag string
+		puuid     string
+		region    string
+		ours      bool
+	}{
+		{name: "friend", tag: "euw", puuid: "puuid-friend", region: "eu1"},
+		{name: "Incoming", tag: "EUW", puuid: "puuid-incoming", region: "eu2"},
+		{name: "Manual", tag: "EUW", puuid: "puuid-manual", region: "eu2"},
+		{name: "Orphan", tag: "EUW", puuid: "puuid-orphan", region: "eu2", ours: true},
+		{name: "Stranger", tag: "EUW"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contact, ours := resolver.findContact(resolver.client(), tt.name, tt.tag)
+			if tt.puuid == "" {
+				if contact != nil {
+					t.Fatalf("found %+v for an unknown account", contact)
+				}
+				return
+			}
+			if contact == nil {
+				t.Fatal("contact not found")
+			}
+			if contact.PUUID != tt.puuid || contact.Region != tt.region || ours != tt.ours {
+				t.Errorf("got %s in %s, ours = %v, want %s in %s, ours = %v", contact.PUUID, contact.Region, ours, tt.puuid, tt.region, tt.ours)
+			}
+		})
+	}
+
+	if hits := fake.Hits(http.MethodPost, "/chat/v4/friendrequests"); hits != 0 {
+		t.Errorf("sent %d friend requests while looking up contacts", hits)
+	}
+}
+
+func TestSendFriendRequest(t *testing.T) {
+	fake, resolver := newFakeResolver(t)
+	fake.AddPlayer(stranger)
+
+	// the event socket connects in the background
+	deadline := time.Now().Add(5 * time.Second)
+	for _, _, ok := resolver.client().SubscribeFriendRequests(); !ok; _, _, ok = resolver.client().SubscribeFriendRequests() {
+		if time.Now().After(deadline) {
+			t.Fatal("event socket never connected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	started := time.Now()
+	req, err := resolver.sendFriendRequest(resolver.client(), "stranger", "euw")
+	if err != nil {
+		t.Fatalf("failed to send friend request: %v", err)
+	}
+	if req.PUUID != stranger.PUUID || req.Region != stranger.Region {
+		t.Errorf("got %s in %s, want %s in %s", req.PUUID, req.Region, stranger.PUUID, stranger.Region)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("took %s, the event should have arrived right away", elapsed)
+	}
+	if _, ok := resolver.journal.Get("stranger", "euw"); !ok {
+		t.Error("sent friend request was not journaled")
+	}
+	if fake.Hits(http.MethodGet, "/chat/v3/friendrequests") != 0 {
+		t.Error("polled although the event arrived")
+	}
+}
+
+func TestSendFriendRequestPolls(t *testing.T) {
+	fake, resolver := newFakeResolver(t)
+	fake.AddPlayer(stranger)
+	resolver.client().Close()
+	resolver.client().events = nil
+
+	req, err := resolver.sendFriendRequest(resolver.client(), "Stranger", "EUW")
+	if err != nil {
+		t.Fatalf("failed to send friend request: %v", err)
+	}
+	if req.PUUID != stranger.PUUID {
+		t.Errorf("got %s, want %s", req.PUUID, stranger.PUUID)
+	}
+}
+
+func TestSendFriendRequestFailures(t *testing.T) {
+	fake, resolver := newFakeResolver(t)
+	fake.AddPlayer(stranger)
+	resolver.eventTimeout = 50 * time.Millisecond
+
+	fake.DropFriendRequests(true)
+	if _, err := resolver.sendFriendRequest(resolver.client(), "Stranger", "EUW"); !errors.Is(err, ErrFriendRequestNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrFriendRequestNotFound)
+	}
+	fake.DropFriendRequests(false)
+
+	fake.Fail(http.MethodPost, "/chat/v4/friendrequests", http.StatusTooManyRequests, 1)
+	if _, err := resolver.sendFriendRequest(resolver.client(), "Stranger", "EUW"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("err = %v, want %v", err, ErrRateLimited)
+	}
+
+	fake.SetDelay("/chat/v4/friendrequests", 100*time.Millisecond)
+	if _, err := resolver.sendFriendRequest(resolver.client(), "Stranger", "EUW"); err != nil {
+		t.Errorf("slow riot client failed the friend request: %v", err)
+	}
+}
+
+func TestHealthAgainstFake(t *testing.T) {
+	fake, resolver := newFakeResolver(t)
+
+	if health := resolver.Health(context.Background()); health.Status != StatusReady || health.GameVersion != fake.GameVersion {
+		t.Errorf("got %s (%s) on %q, want ready on %q", health.Status, health.Detail, health.GameVersion, fake.GameVersion)
+	}
+
+	fake.SetPatching(true)
+	if health := resolver.Health(context.Background()); health.Status != StatusPatching {
+		t.Errorf("status while patching = %s, want %s", health.Status, StatusPatching)
+	}
+
+	fake.SetLoggedOut(true)
+	if health := resolver.Health(context.Background()); health.Status != StatusLoggedOut {
+		t.Errorf("status while logged out = %s, want %s", health.Status, StatusLoggedOut)
+	}
+}
+
+// withFakePD points the resolver's riot api requests at a fake pd server
+func withFakePD(t *testing.T, resolver *Resolver) *pdtest.Server {
+	pd := pdtest.NewServer(pdtest.Fixtures())
+	t.Cleanup(pd.Close)
+	resolver.valClient = valorant.NewClient(pd.URL(), nil, zap.NewNop().Sugar())
+	return pd
+}
+
+func TestResolveAccount(t *testing.T) {
+	fake, resolver := newFakeResolver(t)
+	fake.AddPlayer(zcerno)
+	withFakePD(t, resolver)
+
+	account, err := resolver.ResolveAccount("ZCERNO", "3137")
+	if err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+
+	want := AccountData{
+		PUUID:        zcerno.PUUID,
+		Region:       "eu2",
+		AccountLevel: 50,
+		Name:         "ZCERNO",
+		Tag:          "3137",
+		Card:         "a372c539-41c7-6a41-7064-80a61ccd597b",
+		Title:        "ed96f7bd-4eed-de28-8c93-40bd313a3157",
+	}
+	if *account != want {
+		t.Errorf("got %+v, want %+v", *account, want)
+	}
+
+	if pending := fake.FriendRequests(); len(pending) != 0 {
+		t.Errorf("friend requests left behind: %v", pending)
+	}
+	if entries := resolver.journal.Entries(); len(entries) != 0 {
+		t.Errorf("journal not cleared: %+v", entries)
+	}
+}
+
+func TestResolveAccountFailures(t *testing.T) {
+	fake, resolver := newFakeResolver(t)
+	fake.AddPlayer(zcerno)
+	fake.AddPlayer(noMatches)
+	pd := withFakePD(t, resolver)
+
+	if _, err := resolver.ResolveAccount("abcd", "1234"); !errors.Is(err, ErrNoMatchHistory) {
+		t.Errorf("err = %v, want %v", err, ErrNoMatchHistory)
+	}
+
+	// rejected tokens are refreshed once and the request retried
+	before := fake.Hits(http.MethodGet, "/entitlements/v1/token")
+	pd.Fail("/match-history/", http.StatusUnauthorized, 1)
+	if _, err := resolver.ResolveAccount("zcerno", "3137"); err != nil {
+		t.Errorf("resolve failed after a token refresh: %v", err)
+	}
+	if refreshed := fake.Hits(http.MethodGet, "/entitlements/v1/token") - before; refreshed != 1 {
+		t.Errorf("fetched the token %d times, want 1", refreshed)
+	}
+	if hits := pd.Hits("/eu/match-history/"); hits != 2 {
+		t.Errorf("match history requested %d times, want 2", hits)
+	}
+
+	pd.Fail("/match-details/", http.StatusServiceUnavailable, 1)
+	if _, err := resolver.ResolveAccount("zcerno", "3137"); err == nil {
+		t.Error("resolve succeeded while pd was down")
+	}
+
+	pd.Fail("/match-history/", http.StatusTooManyRequests, 1)
+	if _, err := resolver.ResolveAccount("zcerno", "3137"); !errors.Is(err, valorant.ErrRateLimited) {
+		t.Errorf("err = %v, want %v", err, valorant.ErrRateLimited)
+	}
+	if health := resolver.Health(context.Background()); health.Status != StatusRateLimited {
+		t.Errorf("status after a 429 = %s, want %s", health.Status, StatusRateLimited)
+	}
+
+	if pending := fake.FriendRequests(); len(pending) != 0 {
+		t.Errorf("failed resolves left friend requests behind: %v", pending)
+	}
+}
+
+// a resolve recorded against the fakes replays without them, the way one
+// recorded on a real riot client would
+func TestReplayResolve(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	fake := lcutest.NewServer()
+	fake.AddPlayer(zcerno)
+	pd := pdtest.NewServer(pdtest.Fixtures())
+
+	path := filepath.Join(t.TempDir(), "lockfile")
+	if err := fake.WriteLockfile(path); err != nil {
+		t.Fatal(err)
+	}
+	lockfile, err := ReadLockfile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := cassette.NewRecorder(filepath.Join(t.TempDir(), "resolve.json"), logger)
+	journal, _ := OpenJournal("")
+	resolver := NewResolver(
+		NewClient(lockfile, recorder.Transport(NewTransport()), logger),
+		valorant.NewClient(pd.URL(), recorder.Transport(valorant.NewTransport()), logger),
+		journal, logger,
+	)
+	resolver.pollInterval = 10 * time.Millisecond
+
+	recorded, err := resolver.ResolveAccount("zcerno", "3137")
+	if err != nil {
+		t.Fatalf("failed to resolve while recording: %v", err)
+	}
+	fake.Close()
+	pd.Close()
+
+	replayer := cassette.NewReplayer(recorder.Cassette())
+	resolver = NewResolver(
+		NewClient(&LockfileData{Port: "1", Password: "unused"}, replayer, logger),
+		// pdtest has the shard in the path, real cassettes in the host
+		valorant.NewClient(pd.URL(), replayer, logger),
+		journal, logger,
+	)
+	resolver.pollInterval = 10 * time.Millisecond
+
+	replayed, err := resolver.ResolveAccount("zcerno", "3137")
+	if err != nil {
+		t.Fatalf("failed to resolve from the cassette: %v", err)
+	}
+
+	// everything but the scrubbed puuid comes back
+	if replayed.PUUID == zcerno.PUUID || replayed.PUUID == "" {
+		t.Errorf("puuid %q not scrubbed", replayed.PUUID)
+	}
+	replayed.PUUID = recorded.PUUID
+	if *replayed != *recorded {
+		t.Errorf("replayed %+v, recorded %+v", *replayed, *recorded)
+	}
+	if served := replayer.Served("DELETE /chat/v4/friendrequests"); served != 1 {
+		t.Errorf("friend request deleted %d times, want 1", served)
+	}
+}
@@ -0,0 +1,66 @@
+package lcu
+
+import (
+	"context"
+	"fmt"
+)
+
+type ExternalSession struct {
+	ProductID string `json:"productId"`
+	Version   string `json:"version"`
+	Phase     string `json:"phase"`
+}
+
+// ChatSession is the riot client's chat login, friend requests only work
+// while it is connected
+type ChatSession struct {
+	Loaded   bool   `json:"loaded"`
+	PUUID    string `json:"puuid"`
+	State    string `json:"state"`
+	GameName string `json:"game_name"`
+	GameTag  string `json:"game_tag"`
+}
+
+// GetGameVersion returns the version of the running valorant session
+func (c *Client) GetGameVersion() (string, error) {
+	session, err := c.GetValorantSession()
+	if err != nil {
+		return "", err
+	}
+	if session == nil || session.Version == "" {
+		return "", fmt.Errorf("no valorant session running")
+	}
+	return session.Version, nil
+}
+
+// GetValorantSession returns the valorant product session, nil if the game
+// isn't running
+func (c *Client) GetValorantSession() (*ExternalSession, error) {
+	return c.valorantSession(context.Background())
+}
+
+func (c *Client) valorantSession(ctx context.Context) (*ExternalSession, error) {
+	var sessions map[string]ExternalSession
+	if err := c.getContext(ctx, "/product-session/v1/external-sessions", &sessions); err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if session.ProductID == "valorant" {
+			return &session, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Client) GetChatSession() (*ChatSession, error) {
+	return c.chatSession(context.Background())
+}
+
+func (c *Client) chatSession(ctx context.Context) (*ChatSession, error) {
+	var result ChatSession
+	if err := c.getContext(ctx, "/chat/v1/session", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
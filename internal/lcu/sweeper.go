@@ -0,0 +1,120 @@
+package lcu
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultSweepInterval = time.Minute
+	// orphanAge is how old a journaled friend request has to be before the
+	// sweeper treats it as left behind, well above the length of a resolve
+	orphanAge = 2 * time.Minute
+)
+
+// Sweeper periodically deletes outgoing friend requests the resolver sent but
+// never got to delete, before they pile up to riot's outgoing request cap
+type Sweeper struct {
+	resolver *Resolver
+	interval time.Duration
+	logger   *zap.SugaredLogger
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewSweeper(resolver *Resolver, interval time.Duration, logger *zap.SugaredLogger) *Sweeper {
+	return &Sweeper{
+		resolver: resolver,
+		interval: interval,
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start sweeps right away, which clears what a crash left behind, then every
+// interval
+func (s *Sweeper) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.resolver.SweepFriendRequests(orphanAge)
+
+			select {
+			case <-ticker.C:
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Sweeper) Stop() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+// SweepFriendRequests deletes pending outgoing requests from the journal that
+// are older than maxAge and forgets journal entries whose request is gone.
+// requests the journal doesn't know were sent by a person and are left alone
+func (r *Resolver) SweepFriendRequests(maxAge time.Duration) {
+	lcuClient := r.client()
+	if lcuClient == nil || r.journal == nil {
+		return
+	}
+
+	requests, err := lcuClient.GetFriendRequests()
+	if err != nil {
+		r.logger.Warnw("failed to list friend requests for sweep", "error", err)
+		return
+	}
+
+	pending := make(map[string]bool)
+	backlog := 0
+	for _, req := range requests.Requests {
+		if req.Subscription != "pending_out" {
+			continue
+		}
+		backlog++
+
+		entry, ok := r.journal.Get(req.GameName, req.GameTag)
+		if !ok {
+			continue
+		}
+		pending[journalKey(req.GameName, req.GameTag)] = true
+		if time.Since(entry.SentAt) < maxAge {
+			continue
+		}
+
+		if err := lcuClient.DeleteFriendRequest(req.PUUID); err != nil {
+			r.logger.Warnw("failed to delete orphaned friend request", "puuid", req.PUUID, "error", err)
+			continue
+		}
+		r.logger.Infow("deleted orphaned friend request", "name", req.GameName, "tag", req.GameTag, "sentAt", entry.SentAt)
+		backlog--
+		r.forgetFriendRequest(req.GameName, req.GameTag)
+	}
+
+	for _, entry := range r.journal.Entries() {
+		if !pending[journalKey(entry.GameName, entry.GameTag)] && time.Since(entry.SentAt) >= maxAge {
+			r.forgetFriendRequest(entry.GameName, entry.GameTag)
+		}
+	}
+
+	r.mu.Lock()
+	r.backlog = backlog
+	r.mu.Unlock()
+}
+
+func (r *Resolver) forgetFriendRequest(gameName, gameTag string) {
+	if err := r.journal.Remove(gameName, gameTag); err != nil {
+		r.logger.Warnw("failed to update friend request journal", "error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package lcu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSweepFriendRequests(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+	requests := []FriendRequest{
+		{GameName: "Orphan", GameTag: "EUW", PUUID: "puuid-orphan", Subscription: "pending_out"},
+		{GameName: "InFlight", GameTag: "EUW", PUUID: "puuid-inflight", Subscription: "pending_out"},
+		{GameName: "Stranger", GameTag: "EUW", PUUID: "puuid-stranger", Subscription: "pending_out"},
+		{GameName: "Incoming", GameTag: "EUW", PUUID: "puuid-incoming", Subscription: "pending_in"},
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(FriendRequestsResponse{Requests: requests})
+		case http.MethodDelete:
+			var body RemoveFriendRequestBody
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			deleted = append(deleted, body.PUUID)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range [][2]string{{"orphan", "euw"}, {"InFlight", "EUW"}, {"Declined", "EUW"}} {
+		if err := journal.Add(id[0], id[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	journal.entries[journalKey("orphan", "euw")] = JournalEntry{GameName: "orphan", GameTag: "euw", SentAt: old}
+	journal.entries[journalKey("Declined", "EUW")] = JournalEntry{GameName: "Declined", GameTag: "EUW", SentAt: old}
+
+	resolver := NewResolver(newTestClient(t, server), nil, journal, zap.NewNop().Sugar())
+	resolver.SweepFriendRequests(orphanAge)
+
+	if len(deleted) != 1 || deleted[0] != "puuid-orphan" {
+		t.Errorf("deleted %v, want only the orphaned request", deleted)
+	}
+	if backlog := resolver.Health(context.Background()).FriendRequestBacklog; backlog != 2 {
+		t.Errorf("backlog = %d, want 2", backlog)
+	}
+
+	// the orphan and the declined request are forgotten, on disk too
+	reopened, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := reopened.Entries()
+	if len(entries) != 1 || entries[0].GameName != "InFlight" {
+		t.Errorf("journal after sweep = %+v, want only the in-flight request", entries)
+	}
+}
@@ -0,0 +1,179 @@
+package lcu
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultWatchInterval = 5 * time.Second
+	dialTimeout          = 2 * time.Second
+	// inspectInterval throttles the process fallback while no lockfile is
+	// found, listing processes starts powershell on windows
+	inspectInterval = time.Minute
+)
+
+// Watcher polls for the riot client lockfile and keeps the resolver's LCU
+// client in sync with it. a restarted riot client comes back with a new port
+// and password, so the client is rebuilt whenever the lockfile changes and
+// removed while the riot client is gone or not listening
+type Watcher struct {
+	path     string
+	interval time.Duration
+	resolver *Resolver
+	logger   *zap.SugaredLogger
+
+	// inspect finds the riot client among the running processes, it last ran
+	// at inspected. both are only used by check
+	inspect   func() (*LockfileData, error)
+	inspected time.Time
+
+	mu        sync.Mutex
+	transport http.RoundTripper
+	current   *LockfileData
+	client    *Client
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewWatcher(path string, interval time.Duration, resolver *Resolver, logger *zap.SugaredLogger) *Watcher {
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		resolver: resolver,
+		logger:   logger,
+		inspect:  lockfileFromProcess,
+		done:     make(chan struct{}),
+	}
+}
+
+// SetTransport makes the LCU clients built from now on send their requests
+// through transport, e.g. to record them
+func (w *Watcher) SetTransport(transport http.RoundTripper) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.transport = transport
+}
+
+// Start checks the lockfile once right away, then every interval
+func (w *Watcher) Start() {
+	w.check()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.check()
+			case <-w.done:
+				return
+			}
+		}
+	}()
+}
+
+func (w *Watcher) Stop() {
+	close(w.done)
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.client != nil {
+		w.client.Close()
+	}
+}
+
+func (w *Watcher) check() {
+	lockfile, err := w.findLockfile()
+	if err == nil {
+		err = dialLCU(lockfile)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err != nil {
+		if w.current != nil {
+			w.logger.Warnw("riot client lost", "error", err)
+			w.current = nil
+			w.replaceClient(nil)
+			// look for it among the processes on the next check
+			w.inspected = time.Time{}
+		}
+		return
+	}
+
+	if w.current != nil && sameRiotClient(w.current, lockfile) {
+		return
+	}
+
+	if w.current == nil {
+		w.logger.Infow("riot client detected", "port", lockfile.Port, "path", lockfile.Path)
+	} else {
+		w.logger.Infow("riot client restarted", "oldPort", w.current.Port, "port", lockfile.Port, "path", lockfile.Path)
+	}
+
+	client := NewClient(lockfile, w.transport, w.logger)
+	if err := client.ListenEvents(lockfile); err != nil {
+		w.logger.Warnw("failed to listen for riot client events, resolves will poll", "error", err)
+	}
+
+	w.current = lockfile
+	w.replaceClient(client)
+}
+
+// findLockfile is FindLockfile with the process inspection throttled. a riot
+// client that was found by inspection is kept while it still listens
+func (w *Watcher) findLockfile() (*LockfileData, error) {
+	lockfile, notFound := findLockfileOnDisk(w.path)
+	if lockfile != nil {
+		return lockfile, nil
+	}
+
+	w.mu.Lock()
+	current := w.current
+	w.mu.Unlock()
+	if current != nil && current.fromProcess() {
+		return current, nil
+	}
+
+	if time.Since(w.inspected) < inspectInterval {
+		return nil, notFound
+	}
+	w.inspected = time.Now()
+	return notFound.inspect(w.inspect)
+}
+
+// replaceClient hands the resolver a new client and stops the old one's
+// event socket. resolves still running on the old client fall back to polling
+func (w *Watcher) replaceClient(client *Client) {
+	w.resolver.SetClient(client)
+	if w.client != nil {
+		w.client.Close()
+	}
+	w.client = client
+}
+
+// dialLCU tells a live riot client from a lockfile left behind by one that
+// crashed
+func dialLCU(lockfile *LockfileData) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", lockfile.Port), dialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func sameRiotClient(a, b *LockfileData) bool {
+	return a.PID == b.PID && a.Port == b.Port && a.Password == b.Password
+}
@@ -0,0 +1,124 @@
+package lcu
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func listen(t *testing.T) (net.Listener, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return listener, port
+}
+
+func TestWatcherFollowsRiotClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lockfile")
+	resolver := NewResolver(nil, nil, nil, zap.NewNop().Sugar())
+	watcher := NewWatcher(path, time.Hour, resolver, zap.NewNop().Sugar())
+	defer watcher.Stop()
+
+	watcher.check()
+	if resolver.Available() {
+		t.Fatal("resolver available without a lockfile")
+	}
+
+	_, port := listen(t)
+	writeLockfile(t, path, fmt.Sprintf("Riot Client:100:%s:first:https", port))
+	watcher.check()
+	first := resolver.client()
+	if first == nil {
+		t.Fatal("resolver not available after the riot client started")
+	}
+
+	watcher.check()
+	if resolver.client() != first {
+		t.Error("client rebuilt although the lockfile did not change")
+	}
+
+	// restart on a new port with a new password
+	listener, port := listen(t)
+	writeLockfile(t, path, fmt.Sprintf("Riot Client:200:%s:second:https", port))
+	watcher.check()
+	second := resolver.client()
+	if second == nil || second == first {
+		t.Fatal("client not rebuilt after the riot client restarted")
+	}
+	if want := (&LockfileData{Password: "second"}).GetAuthHeader(); second.authHeader != want {
+		t.Errorf("client uses auth header %q, want %q", second.authHeader, want)
+	}
+
+	// a stale lockfile of a crashed riot client
+	listener.Close()
+	watcher.check()
+	if resolver.Available() {
+		t.Error("resolver available while nothing listens on the lockfile port")
+	}
+
+	os.Remove(path)
+	watcher.check()
+	if resolver.Available() {
+		t.Error("resolver available after the lockfile was removed")
+	}
+}
+
+func TestWatcherThrottlesProcessInspection(t *testing.T) {
+	resolver := NewResolver(nil, nil, nil, zap.NewNop().Sugar())
+	watcher := NewWatcher(filepath.Join(t.TempDir(), "lockfile"), time.Hour, resolver, zap.NewNop().Sugar())
+	defer watcher.Stop()
+
+	var inspections int
+	var running *LockfileData
+	watcher.inspect = func() (*LockfileData, error) {
+		inspections++
+		if running == nil {
+			return nil, errors.New("no riot client process")
+		}
+		return running, nil
+	}
+
+	// the first miss inspects, the next ones wait for the interval
+	for range 3 {
+		watcher.check()
+	}
+	if inspections != 1 {
+		t.Errorf("inspected processes %d times while nothing ran, want 1", inspections)
+	}
+
+	listener, port := listen(t)
+	running = &LockfileData{PID: "100", Port: port, Password: "secret", Path: "process 100"}
+	watcher.inspected = time.Time{}
+	watcher.check()
+	if !resolver.Available() {
+		t.Fatal("riot client found by inspection not used")
+	}
+
+	// kept without inspecting again while it listens
+	watcher.check()
+	if inspections != 2 || !resolver.Available() {
+		t.Errorf("inspected %d times with available %v, want 2 and the client kept", inspections, resolver.Available())
+	}
+
+	// once it's gone the next check looks for it right away
+	listener.Close()
+	running = nil
+	watcher.check()
+	if resolver.Available() {
+		t.Error("resolver available after the riot client quit")
+	}
+	watcher.check()
+	if inspections != 3 {
+		t.Errorf("inspected %d times after the riot client quit, want 3", inspections)
+	}
+}
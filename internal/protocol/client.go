@@ -1,16 +1,28 @@
 package protocol
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
 	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/lcu"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/valorant"
 )
 
+// heartbeatInterval is how often the node probes the riot client and tells
+// the master how it is doing
+const heartbeatInterval = 10 * time.Second
+
+// healthProbeTimeout bounds a probe of the riot client, a hung one must not
+// hold up heartbeats or requests
+const healthProbeTimeout = 5 * time.Second
+
 type Client struct {
 	serverAddress string
 	clientID      string
@@ -19,6 +31,19 @@ type Client struct {
 	resolver      *lcu.Resolver
 	logger        *zap.SugaredLogger
 	done          chan struct{}
+
+	mu     sync.Mutex
+	health lcu.Health
+	// changed wakes the run loop to report a new status right away
+	changed chan struct{}
+}
+
+var nodeStatuses = map[lcu.Status]v1.NodeStatus{
+	lcu.StatusUnavailable: v1.NodeStatus_NODE_STATUS_UNAVAILABLE,
+	lcu.StatusLoggedOut:   v1.NodeStatus_NODE_STATUS_LOGGED_OUT,
+	lcu.StatusPatching:    v1.NodeStatus_NODE_STATUS_PATCHING,
+	lcu.StatusRateLimited: v1.NodeStatus_NODE_STATUS_RATE_LIMITED,
+	lcu.StatusReady:       v1.NodeStatus_NODE_STATUS_READY,
 }
 
 func NewClient(serverAddress, clientID, version string, resolver *lcu.Resolver, logger *zap.SugaredLogger) *Client {
@@ -29,6 +54,7 @@ func NewClient(serverAddress, clientID, version string, resolver *lcu.Resolver,
 		resolver:      resolver,
 		logger:        logger,
 		done:          make(chan struct{}),
+		changed:       make(chan struct{}, 1),
 	}
 }
 
@@ -57,30 +83,25 @@ func (c *Client) Connect() error {
 
 	c.logger.Infow("registration sent")
 
-	lcuAvailable := c.isLCUAvailable()
-	initialHeartbeat := &v1.Message{
-		Id: "heartbeat-initial",
-		Payload: &v1.Message_ClientHeartbeat{
-			ClientHeartbeat: &v1.ClientHeartbeat{
-				Timestamp:    time.Now().UnixMilli(),
-				LcuAvailable: lcuAvailable,
-			},
-		},
-	}
-
+	c.probe()
+	initialHeartbeat := c.heartbeat("heartbeat-initial")
 	if err := WriteMessage(conn, initialHeartbeat); err != nil {
 		c.logger.Warnw("failed to send initial heartbeat", "error", err)
 	} else {
-		c.logger.Infow("initial heartbeat sent", "lcuAvailable", lcuAvailable)
+		c.logger.Infow("initial heartbeat sent", "status", c.lastHealth().Status)
 	}
 
 	return nil
 }
 
 func (c *Client) Run() error {
-	ticker := time.NewTicker(10 * time.Second)
+	ticker := time.NewTicker(heartbeatInterval)
 	defer ticker.Stop()
 
+	stopProbes := make(chan struct{})
+	defer close(stopProbes)
+	go c.probeHealth(stopProbes)
+
 	messages := make(chan *v1.Message)
 	errors := make(chan error)
 
@@ -98,18 +119,12 @@ func (c *Client) Run() error {
 	for {
 		select {
 		case <-ticker.C:
-			lcuAvailable := c.isLCUAvailable()
-			msg := &v1.Message{
-				Id: "heartbeat",
-				Payload: &v1.Message_ClientHeartbeat{
-					ClientHeartbeat: &v1.ClientHeartbeat{
-						Timestamp:    time.Now().UnixMilli(),
-						LcuAvailable: lcuAvailable,
-					},
-				},
+			if err := WriteMessage(c.conn, c.heartbeat("heartbeat")); err != nil {
+				c.logger.Errorw("failed to send heartbeat", "error", err)
 			}
 
-			if err := WriteMessage(c.conn, msg); err != nil {
+		case <-c.changed:
+			if err := WriteMessage(c.conn, c.heartbeat("heartbeat")); err != nil {
 				c.logger.Errorw("failed to send heartbeat", "error", err)
 			}
 
@@ -142,7 +157,7 @@ func (c *Client) handleResolveRequest(requestID string, req *v1.ResolveAccountRe
 			Id: requestID,
 			Payload: &v1.Message_ErrorResponse{
 				ErrorResponse: &v1.ErrorResponse{
-					Code:    "RESOLVE_FAILED",
+					Code:    resolveErrorCode(err),
 					Message: err.Error(),
 				},
 			},
@@ -168,9 +183,83 @@ func (c *Client) handleResolveRequest(requestID string, req *v1.ResolveAccountRe
 	}
 }
 
-func (c *Client) isLCUAvailable() bool {
-	_, err := lcu.ReadLockfile()
-	return err == nil
+func resolveErrorCode(err error) string {
+	switch {
+	case errors.Is(err, lcu.ErrFriendRequestNotFound):
+		return ErrorCodeNotFound
+	case errors.Is(err, lcu.ErrNoMatchHistory):
+		return ErrorCodeNoMatchHistory
+	case errors.Is(err, lcu.ErrRateLimited), errors.Is(err, valorant.ErrRateLimited):
+		return ErrorCodeRateLimited
+	default:
+		return ErrorCodeResolveFailed
+	}
+}
+
+// probeHealth probes the riot client off the request loop until stop is
+// closed, heartbeats send the last result
+func (c *Client) probeHealth(stop <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if c.probe() {
+				select {
+				case c.changed <- struct{}{}:
+				default:
+				}
+			}
+		case <-stop:
+			return
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// probe stores a fresh health probe and reports whether the status changed
+func (c *Client) probe() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), healthProbeTimeout)
+	defer cancel()
+	health := c.resolver.Health(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := health.Status != c.health.Status
+	if changed {
+		c.logger.Infow("node status changed", "from", c.health.Status, "to", health.Status, "detail", health.Detail)
+	}
+	c.health = health
+	return changed
+}
+
+func (c *Client) lastHealth() lcu.Health {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.health
+}
+
+// heartbeat reports the last health probe so the master only routes to the
+// node while it can actually resolve accounts
+func (c *Client) heartbeat(id string) *v1.Message {
+	health := c.lastHealth()
+
+	heartbeat := &v1.ClientHeartbeat{
+		Timestamp:    time.Now().UnixMilli(),
+		LcuAvailable: health.Status != lcu.StatusUnavailable,
+		GameVersion:  health.GameVersion,
+		Status:       nodeStatuses[health.Status],
+		StatusDetail: health.Detail,
+
+		FriendRequestBacklog: int64(health.FriendRequestBacklog),
+	}
+
+	return &v1.Message{
+		Id:      id,
+		Payload: &v1.Message_ClientHeartbeat{ClientHeartbeat: heartbeat},
+	}
 }
 
 func (c *Client) Stop() error {
@@ -1,25 +1,79 @@
 package protocol
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	v1 "github.com/ferrarinobrakes/unofficial-valorant-api/gen"
+	"github.com/ferrarinobrakes/unofficial-valorant-api/internal/db"
 	"github.com/google/uuid"
 )
 
+const storeTimeout = 5 * time.Second
+
+// HeartbeatTimeout is how long a node stays eligible for requests after its
+// last heartbeat
+const HeartbeatTimeout = 30 * time.Second
+
+// DefaultRequestTimeout is how long the master waits for a node to answer a
+// resolve
+const DefaultRequestTimeout = 60 * time.Second
+
+const (
+	ErrorCodeResolveFailed  = "RESOLVE_FAILED"
+	ErrorCodeNotFound       = "NOT_FOUND"
+	ErrorCodeNoMatchHistory = "NO_MATCH_HISTORY"
+	ErrorCodeRateLimited    = "RATE_LIMITED"
+	ErrorCodeNoClients      = "NO_AVAILABLE_CLIENTS"
+	ErrorCodeTimeout        = "TIMEOUT"
+)
+
+var (
+	ErrNoAvailableClients = errors.New("no available clients")
+	ErrRequestTimeout     = errors.New("request timed out")
+)
+
 type ClientConnection struct {
-	ID             string
-	Conn           net.Conn
-	Version        string
-	LCUAvailable   bool
-	LastHeartbeat  time.Time
-	PendingRequest chan *Request
+	ID            string
+	Conn          net.Conn
+	Version       string
+	GameVersion   string
+	RemoteAddr    string
+	LCUAvailable  bool
+	Status        v1.NodeStatus
+	StatusDetail  string
+	LastHeartbeat time.Time
+
+	// busy holds a token while a resolve is in flight, a node answers one
+	// at a time. pending is that resolve, guarded by Server.mu
+	busy    chan struct{}
+	pending *Request
+}
+
+// Ready reports whether the node's last health probe found it able to
+// resolve accounts. nodes without a health probe only report the lockfile
+func (c *ClientConnection) Ready() bool {
+	if c.Status == v1.NodeStatus_NODE_STATUS_UNSPECIFIED {
+		return c.LCUAvailable
+	}
+	return c.Status == v1.NodeStatus_NODE_STATUS_READY
+}
+
+// StatusName is how a node status is stored and shown, e.g. "rate_limited"
+func StatusName(status v1.NodeStatus) string {
+	if status == v1.NodeStatus_NODE_STATUS_UNSPECIFIED {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(status.String(), "NODE_STATUS_"))
 }
+
 type Request struct {
 	ID       string
 	GameName string
@@ -27,23 +81,40 @@ type Request struct {
 	Response chan *Response
 }
 type Response struct {
+	ClientID     string
 	PUUID        string
 	Region       string
 	AccountLevel int
 	Card         string
 	Title        string
 	Error        string
+	ErrorCode    string
+}
+
+// Failed reports whether the node could not answer, as opposed to answering
+// that the account doesn't exist or has no matches
+func (r *Response) Failed() bool {
+	if r.Error == "" {
+		return false
+	}
+	return r.ErrorCode != ErrorCodeNotFound && r.ErrorCode != ErrorCodeNoMatchHistory
 }
 
 type Server struct {
 	listener net.Listener
 	clients  map[string]*ClientConnection
 	mu       sync.RWMutex
+	store    db.Querier
 	logger   *zap.SugaredLogger
 	done     chan struct{}
+
+	requestTimeout time.Duration
 }
 
-func NewServer(port int, logger *zap.SugaredLogger) (*Server, error) {
+// NewServer starts listening for nodes, port 0 picks a free one. registrations,
+// heartbeats and request outcomes are mirrored to store so the fleet survives
+// master restarts
+func NewServer(port int, store db.Querier, logger *zap.SugaredLogger) (*Server, error) {
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return nil, fmt.Errorf("failed to start TCP server: %w", err)
@@ -54,11 +125,27 @@ func NewServer(port int, logger *zap.SugaredLogger) (*Server, error) {
 	return &Server{
 		listener: listener,
 		clients:  make(map[string]*ClientConnection),
+		store:    store,
 		logger:   logger,
 		done:     make(chan struct{}),
+
+		requestTimeout: DefaultRequestTimeout,
 	}, nil
 }
 
+// Addr is the address nodes connect to
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// SetRequestTimeout changes how long resolves wait for a node, for requests
+// sent from now on
+func (s *Server) SetRequestTimeout(timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestTimeout = timeout
+}
+
 func (s *Server) Start() error {
 	for {
 		conn, err := s.listener.Accept()
@@ -96,28 +183,59 @@ func (s *Server) handleClient(conn net.Conn) {
 			s.logger.Infow("client registered", "clientID", clientID, "version", payload.ClientRegister.Version)
 
 			client = &ClientConnection{
-				ID:             clientID,
-				Conn:           conn,
-				Version:        payload.ClientRegister.Version,
-				LastHeartbeat:  time.Now(),
-				PendingRequest: make(chan *Request, 1),
+				ID:            clientID,
+				Conn:          conn,
+				Version:       payload.ClientRegister.Version,
+				RemoteAddr:    conn.RemoteAddr().String(),
+				LastHeartbeat: time.Now(),
+				busy:          make(chan struct{}, 1),
 			}
 
 			s.mu.Lock()
 			s.clients[clientID] = client
 			s.mu.Unlock()
 
+			s.persist("register", clientID, func(ctx context.Context) error {
+				return s.store.RegisterClient(ctx, db.RegisterClientParams{
+					ClientID:      clientID,
+					Version:       client.Version,
+					RemoteAddress: client.RemoteAddr,
+				})
+			})
+
 		case *v1.Message_ClientHeartbeat:
 			if client != nil {
+				heartbeat := payload.ClientHeartbeat
+
+				s.mu.Lock()
 				client.LastHeartbeat = time.Now()
-				client.LCUAvailable = payload.ClientHeartbeat.LcuAvailable
-				s.logger.Debugw("heartbeat received", "clientID", clientID, "lcuAvailable", client.LCUAvailable)
+				client.LCUAvailable = heartbeat.LcuAvailable
+				client.GameVersion = heartbeat.GameVersion
+				if client.Status != heartbeat.Status {
+					s.logger.Infow("node status changed", "clientID", clientID, "status", StatusName(heartbeat.Status), "detail", heartbeat.StatusDetail)
+				}
+				client.Status = heartbeat.Status
+				client.StatusDetail = heartbeat.StatusDetail
+				s.mu.Unlock()
+
+				s.logger.Debugw("heartbeat received", "clientID", clientID, "lcuAvailable", heartbeat.LcuAvailable, "status", StatusName(heartbeat.Status))
+
+				s.persist("heartbeat", clientID, func(ctx context.Context) error {
+					return s.store.UpdateClientHeartbeat(ctx, db.UpdateClientHeartbeatParams{
+						LcuAvailable: heartbeat.LcuAvailable,
+						GameVersion:  heartbeat.GameVersion,
+						Status:       StatusName(heartbeat.Status),
+						StatusDetail: heartbeat.StatusDetail,
+						ClientID:     clientID,
+
+						FriendRequestBacklog: heartbeat.FriendRequestBacklog,
+					})
+				})
 			}
 
 		case *v1.Message_ResolveAccountResponse:
 			if client != nil {
-				select {
-				case req := <-client.PendingRequest:
+				if req := s.takePending(client, msg.Id); req != nil {
 					req.Response <- &Response{
 						PUUID:        payload.ResolveAccountResponse.Puuid,
 						Region:       payload.ResolveAccountResponse.Region,
@@ -125,20 +243,29 @@ func (s *Server) handleClient(conn net.Conn) {
 						Card:         payload.ResolveAccountResponse.Card,
 						Title:        payload.ResolveAccountResponse.Title,
 					}
-				default:
-					s.logger.Warnw("received response with no pending request", "clientID", clientID)
+				} else {
+					s.logger.Warnw("received response with no pending request", "clientID", clientID, "requestID", msg.Id)
 				}
 			}
 
 		case *v1.Message_ErrorResponse:
 			if client != nil {
-				select {
-				case req := <-client.PendingRequest:
+				// stop routing to a rate limited node right away instead of
+				// waiting for its next heartbeat
+				if payload.ErrorResponse.Code == ErrorCodeRateLimited {
+					s.mu.Lock()
+					client.Status = v1.NodeStatus_NODE_STATUS_RATE_LIMITED
+					client.StatusDetail = payload.ErrorResponse.Message
+					s.mu.Unlock()
+				}
+
+				if req := s.takePending(client, msg.Id); req != nil {
 					req.Response <- &Response{
-						Error: payload.ErrorResponse.Message,
+						Error:     payload.ErrorResponse.Message,
+						ErrorCode: payload.ErrorResponse.Code,
 					}
-				default:
-					s.logger.Warnw("received error with no pending request", "clientID", clientID)
+				} else {
+					s.logger.Warnw("received error with no pending request", "clientID", clientID, "requestID", msg.Id)
 				}
 			}
 		}
@@ -152,21 +279,26 @@ func (s *Server) handleClient(conn net.Conn) {
 	}
 }
 
+// ResolveAccount sends the lookup to an available client and waits for its
+// answer. once a client was picked the response names it, even on error
 func (s *Server) ResolveAccount(gameName, gameTag string) (*Response, error) {
 	s.mu.RLock()
 	var client *ClientConnection
 	for _, c := range s.clients {
-		if c.LCUAvailable && time.Since(c.LastHeartbeat) < 30*time.Second {
+		if c.Ready() && time.Since(c.LastHeartbeat) < HeartbeatTimeout {
 			client = c
 			break
 		}
 	}
+	timeout := s.requestTimeout
 	s.mu.RUnlock()
 
 	if client == nil {
-		return nil, fmt.Errorf("no available clients")
+		return nil, ErrNoAvailableClients
 	}
 
+	started := time.Now()
+
 	req := &Request{
 		ID:       uuid.New().String(),
 		GameName: gameName,
@@ -184,20 +316,93 @@ func (s *Server) ResolveAccount(gameName, gameTag string) (*Response, error) {
 		},
 	}
 
-	client.PendingRequest <- req
+	// the timeout covers waiting for the node to finish its previous resolve
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case client.busy <- struct{}{}:
+	case <-timer.C:
+		s.recordRequest(client.ID, started, true, ErrRequestTimeout.Error())
+		return &Response{ClientID: client.ID}, ErrRequestTimeout
+	}
+	defer func() { <-client.busy }()
+
+	s.mu.Lock()
+	client.pending = req
+	s.mu.Unlock()
+	// a timed out resolve leaves nothing behind, its late answer no longer
+	// matches and is dropped
+	defer s.takePending(client, req.ID)
 
 	if err := WriteMessage(client.Conn, msg); err != nil {
-		return nil, fmt.Errorf("failed to send request to client: %w", err)
+		err = fmt.Errorf("failed to send request to client: %w", err)
+		s.recordRequest(client.ID, started, true, err.Error())
+		return &Response{ClientID: client.ID}, err
 	}
 
 	s.logger.Infow("request sent to client", "clientID", client.ID, "name", gameName, "tag", gameTag)
 
 	select {
 	case resp := <-req.Response:
+		resp.ClientID = client.ID
+		s.recordRequest(client.ID, started, resp.Failed(), resp.Error)
 		return resp, nil
-	case <-time.After(60 * time.Second):
-		return nil, fmt.Errorf("request timed out")
+	case <-timer.C:
+		s.recordRequest(client.ID, started, true, ErrRequestTimeout.Error())
+		return &Response{ClientID: client.ID}, ErrRequestTimeout
+	}
+}
+
+// takePending hands out the node's pending resolve if the answer is for it
+func (s *Server) takePending(client *ClientConnection, requestID string) *Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req := client.pending
+	if req == nil || req.ID != requestID {
+		return nil
+	}
+	client.pending = nil
+	return req
+}
+
+func (s *Server) recordRequest(clientID string, started time.Time, failed bool, errMsg string) {
+	params := db.RecordClientRequestParams{
+		LatencyMs: time.Since(started).Milliseconds(),
+		ClientID:  clientID,
+	}
+	if failed {
+		params.Failures = 1
+		params.LastError = &errMsg
+	}
+
+	s.persist("request", clientID, func(ctx context.Context) error {
+		return s.store.RecordClientRequest(ctx, params)
+	})
+}
+
+// persist mirrors a node event to the store. failures are logged and
+// otherwise ignored, the in-memory registry stays authoritative for routing
+func (s *Server) persist(event, clientID string, fn func(ctx context.Context) error) {
+	if s.store == nil {
+		return
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := fn(ctx); err != nil {
+		s.logger.Warnw("failed to persist node event", "event", event, "clientID", clientID, "error", err)
+	}
+}
+
+// IsConnected reports whether the node currently holds a connection
+func (s *Server) IsConnected(clientID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.clients[clientID]
+	return ok
 }
 
 func (s *Server) GetClientCount() int {
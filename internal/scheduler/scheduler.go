@@ -0,0 +1,233 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Timeout bounds a single run, 0 means no limit
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type JobStatus struct {
+	Name         string     `json:"name"`
+	Interval     string     `json:"interval"`
+	Running      bool       `json:"running"`
+	Runs         int        `json:"runs"`
+	Failures     int        `json:"failures"`
+	Skipped      int        `json:"skipped"`
+	LastStarted  *time.Time `json:"last_started,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	NextRun      *time.Time `json:"next_run,omitempty"`
+}
+
+// Store persists when each job last started, so a restart resumes the schedule
+// instead of pushing every job a full interval out
+type Store interface {
+	LastRuns(ctx context.Context) (map[string]time.Time, error)
+	RecordRun(ctx context.Context, job string, started time.Time) error
+}
+
+type scheduledJob struct {
+	job Job
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+// Scheduler runs jobs on fixed intervals. a job whose previous run hasn't
+// finished when it is due again is skipped instead of run twice
+type Scheduler struct {
+	jobs   []*scheduledJob
+	store  Store
+	logger *zap.SugaredLogger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler. without a store every job runs once at Start,
+// with one a job only runs at Start when it is due
+func New(store Store, logger *zap.SugaredLogger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		store:  store,
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers a job, jobs with a zero interval are ignored so they can be
+// disabled from config. must be called before Start
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Infow("job disabled", "job", job.Name)
+		return
+	}
+
+	s.jobs = append(s.jobs, &scheduledJob{
+		job: job,
+		status: JobStatus{
+			Name:     job.Name,
+			Interval: job.Interval.String(),
+		},
+	})
+}
+
+func (s *Scheduler) Start() {
+	lastRuns := s.lastRuns()
+	for _, j := range s.jobs {
+		var delay time.Duration
+		if lastRun, ok := lastRuns[j.job.Name]; ok {
+			j.mu.Lock()
+			j.status.LastStarted = &lastRun
+			j.mu.Unlock()
+			delay = time.Until(lastRun.Add(j.job.Interval))
+		}
+
+		s.wg.Add(1)
+		go s.loop(j, max(delay, 0))
+	}
+}
+
+// lastRuns loads the persisted schedule, jobs it can't tell about are due now
+func (s *Scheduler) lastRuns() map[string]time.Time {
+	if s.store == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+
+	lastRuns, err := s.store.LastRuns(ctx)
+	if err != nil {
+		s.logger.Warnw("failed to load last job runs, running every job now", "error", err)
+		return nil
+	}
+	return lastRuns
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) Status() []JobStatus {
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		statuses = append(statuses, j.status)
+		j.mu.Unlock()
+	}
+	return statuses
+}
+
+func (s *Scheduler) loop(j *scheduledJob, delay time.Duration) {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	s.setNextRun(j, delay)
+
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for {
+		select {
+		case <-timer.C:
+			timer.Reset(j.job.Interval)
+			s.setNextRun(j, j.job.Interval)
+
+			j.mu.Lock()
+			if j.status.Running {
+				j.status.Skipped++
+				j.mu.Unlock()
+				s.logger.Warnw("job still running, skipping", "job", j.job.Name)
+				continue
+			}
+			j.status.Running = true
+			j.mu.Unlock()
+
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				s.run(j)
+			}()
+
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Scheduler) run(j *scheduledJob) {
+	ctx := s.ctx
+	if j.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.job.Timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	s.logger.Debugw("job started", "job", j.job.Name)
+
+	err := j.job.Run(ctx)
+	duration := time.Since(started)
+	// a run cut short by Stop is due again after the restart
+	if s.ctx.Err() == nil {
+		s.recordRun(j, started)
+	}
+
+	j.mu.Lock()
+	j.status.Running = false
+	j.status.Runs++
+	j.status.LastStarted = &started
+	j.status.LastDuration = duration.String()
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	}
+	j.mu.Unlock()
+
+	if err != nil {
+		s.logger.Errorw("job failed", "job", j.job.Name, "duration", duration, "error", err)
+		return
+	}
+	s.logger.Infow("job finished", "job", j.job.Name, "duration", duration)
+}
+
+// recordRun persists the start of a run, failed runs included, they are
+// retried on the next interval like any other
+func (s *Scheduler) recordRun(j *scheduledJob, started time.Time) {
+	if s.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+
+	if err := s.store.RecordRun(ctx, j.job.Name, started); err != nil {
+		s.logger.Warnw("failed to record job run", "job", j.job.Name, "error", err)
+	}
+}
+
+func (s *Scheduler) setNextRun(j *scheduledJob, in time.Duration) {
+	next := time.Now().Add(in)
+
+	j.mu.Lock()
+	j.status.NextRun = &next
+	j.mu.Unlock()
+}
@@ -0,0 +1,207 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	runs map[string]time.Time
+}
+
+func (s *memoryStore) LastRuns(ctx context.Context) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make(map[string]time.Time, len(s.runs))
+	for name, run := range s.runs {
+		runs[name] = run
+	}
+	return runs, nil
+}
+
+func (s *memoryStore) RecordRun(ctx context.Context, job string, started time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runs[job] = started
+	return nil
+}
+
+func (s *memoryStore) lastRun(job string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[job]
+	return run, ok
+}
+
+func status(t *testing.T, s *Scheduler, name string) JobStatus {
+	t.Helper()
+	for _, status := range s.Status() {
+		if status.Name == name {
+			return status
+		}
+	}
+	t.Fatalf("job %s not registered", name)
+	return JobStatus{}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunsOnStart(t *testing.T) {
+	s := New(nil, zap.NewNop().Sugar())
+	var runs atomic.Int32
+	s.Add(Job{Name: "hourly", Interval: time.Hour, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	s.Add(Job{Name: "disabled", Run: func(ctx context.Context) error {
+		t.Error("disabled job ran")
+		return nil
+	}})
+
+	s.Start()
+	defer s.Stop()
+
+	waitFor(t, "the first run", func() bool { return status(t, s, "hourly").Runs == 1 })
+	if len(s.Status()) != 1 {
+		t.Errorf("got %d jobs, want the disabled one left out", len(s.Status()))
+	}
+	if runs.Load() != 1 {
+		t.Errorf("ran %d times, want 1", runs.Load())
+	}
+}
+
+func TestResumesSchedule(t *testing.T) {
+	recent := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	store := &memoryStore{runs: map[string]time.Time{
+		"recent":  recent,
+		"overdue": time.Now().Add(-2 * time.Hour),
+	}}
+
+	s := New(store, zap.NewNop().Sugar())
+	var recentRuns atomic.Int32
+	s.Add(Job{Name: "recent", Interval: time.Hour, Run: func(ctx context.Context) error {
+		recentRuns.Add(1)
+		return nil
+	}})
+	s.Add(Job{Name: "overdue", Interval: time.Hour, Run: func(ctx context.Context) error { return nil }})
+	s.Add(Job{Name: "new", Interval: time.Hour, Run: func(ctx context.Context) error { return nil }})
+
+	s.Start()
+	defer s.Stop()
+
+	waitFor(t, "the due jobs", func() bool {
+		return status(t, s, "overdue").Runs == 1 && status(t, s, "new").Runs == 1
+	})
+	for _, name := range []string{"overdue", "new"} {
+		run, ok := store.lastRun(name)
+		if !ok || time.Since(run) > time.Minute {
+			t.Errorf("run of %s not recorded, have %v", name, run)
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if recentRuns.Load() != 0 {
+		t.Error("job ran before its interval was up")
+	}
+
+	st := status(t, s, "recent")
+	if st.LastStarted == nil || !st.LastStarted.Equal(recent) {
+		t.Errorf("last started = %v, want the stored %v", st.LastStarted, recent)
+	}
+	if want := recent.Add(time.Hour); st.NextRun == nil || st.NextRun.Sub(want).Abs() > time.Second {
+		t.Errorf("next run = %v, want %v", st.NextRun, want)
+	}
+}
+
+func TestSkipsOverlappingRuns(t *testing.T) {
+	s := New(nil, zap.NewNop().Sugar())
+
+	release := make(chan struct{})
+	var running, overlapped atomic.Int32
+	s.Add(Job{Name: "slow", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		if running.Add(1) > 1 {
+			overlapped.Add(1)
+		}
+		defer running.Add(-1)
+		<-release
+		return nil
+	}})
+
+	s.Start()
+	waitFor(t, "skipped runs", func() bool { return status(t, s, "slow").Skipped >= 3 })
+
+	st := status(t, s, "slow")
+	if !st.Running || st.Runs != 0 {
+		t.Errorf("got running %v with %d runs, want the first run still going", st.Running, st.Runs)
+	}
+
+	close(release)
+	waitFor(t, "the first run", func() bool { return status(t, s, "slow").Runs >= 1 })
+	s.Stop()
+
+	if overlapped.Load() != 0 {
+		t.Errorf("%d runs overlapped", overlapped.Load())
+	}
+}
+
+func TestStatusReportsFailures(t *testing.T) {
+	s := New(nil, zap.NewNop().Sugar())
+	s.Add(Job{Name: "broken", Interval: time.Hour, Run: func(ctx context.Context) error {
+		return errors.New("disk full")
+	}})
+
+	s.Start()
+	defer s.Stop()
+
+	waitFor(t, "the failed run", func() bool { return status(t, s, "broken").Failures == 1 })
+
+	st := status(t, s, "broken")
+	if st.Running || st.Runs != 1 || st.LastError != "disk full" || st.Interval != "1h0m0s" {
+		t.Errorf("unexpected status %+v", st)
+	}
+	if st.LastStarted == nil || st.LastDuration == "" {
+		t.Errorf("last run not reported: %+v", st)
+	}
+	if st.NextRun == nil || time.Until(*st.NextRun) < 59*time.Minute {
+		t.Errorf("next run = %v, want an hour out", st.NextRun)
+	}
+}
+
+func TestStopCancelsRuns(t *testing.T) {
+	store := &memoryStore{runs: map[string]time.Time{}}
+	s := New(store, zap.NewNop().Sugar())
+
+	started := make(chan struct{})
+	s.Add(Job{Name: "long", Interval: time.Hour, Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	s.Start()
+	<-started
+	s.Stop()
+
+	if _, ok := store.lastRun("long"); ok {
+		t.Error("run cut short by Stop was recorded")
+	}
+}
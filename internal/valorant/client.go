@@ -3,30 +3,60 @@ package valorant
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 )
 
+// DefaultPDURL is riot's player data service, {shard} is replaced with the
+// shard of the player
+const DefaultPDURL = "https://pd.{shard}.a.pvp.net"
+
 const ClientPlatform = "ew0KCSJwbGF0Zm9ybVR5cGUiOiAiUEMiLA0KCSJwbGF0Zm9ybU9TIjogIldpbmRvd3MiLA0KCSJwbGF0Zm9ybU9TVmVyc2lvbiI6ICIxMC4wLjE5MDQyLjEuMjU2LjY0Yml0IiwNCgkicGxhdGZvcm1DaGlwc2V0IjogIlVua25vd24iDQp9"
 
+var (
+	// ErrRateLimited is returned when the riot api answers with 429
+	ErrRateLimited = errors.New("rate limited by riot api")
+	// ErrUnauthorized is returned when the riot api rejects the tokens
+	ErrUnauthorized = errors.New("tokens rejected by riot api")
+)
+
 type Client struct {
+	pdURL      string
 	httpClient *http.Client
 	logger     *zap.SugaredLogger
 }
 
-func NewClient(logger *zap.SugaredLogger) *Client {
+// NewTransport is how the riot api is reached by default
+func NewTransport() http.RoundTripper {
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+}
+
+// NewClient creates a riot api client. pdURL is the player data base url with
+// a {shard} placeholder, empty uses DefaultPDURL. requests go through
+// transport or through NewTransport when it is nil
+func NewClient(pdURL string, transport http.RoundTripper, logger *zap.SugaredLogger) *Client {
+	if pdURL == "" {
+		pdURL = DefaultPDURL
+	}
+	if transport == nil {
+		transport = NewTransport()
+	}
+
 	return &Client{
+		pdURL: strings.TrimRight(pdURL, "/"),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					MinVersion: tls.VersionTLS12,
-				},
-			},
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
 		logger: logger,
 	}
@@ -64,12 +94,23 @@ func (c *Client) get(url string, accessToken, entitlementToken string, result in
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests:
+			return fmt.Errorf("%w: %s", ErrRateLimited, string(body))
+		case http.StatusUnauthorized:
+			return fmt.Errorf("%w: %s", ErrUnauthorized, string(body))
+		}
 		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
 	}
 
 	return json.NewDecoder(resp.Body).Decode(result)
 }
 
+// pd returns the player data url of path on shard
+func (c *Client) pd(shard, path string) string {
+	return strings.ReplaceAll(c.pdURL, "{shard}", shard) + path
+}
+
 func RegionToShard(region string) string {
 	switch region {
 	case "na1", "na2", "na3", "latam", "br":
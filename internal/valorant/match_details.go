@@ -21,7 +21,7 @@ type MatchDetailsResponse struct {
 }
 
 func (c *Client) GetMatchDetails(shard, matchID, accessToken, entitlementToken string) (*MatchDetailsResponse, error) {
-	url := fmt.Sprintf("https://pd.%s.a.pvp.net/match-details/v1/matches/%s", shard, matchID)
+	url := c.pd(shard, fmt.Sprintf("/match-details/v1/matches/%s", matchID))
 
 	var result MatchDetailsResponse
 	err := c.get(url, accessToken, entitlementToken, &result)
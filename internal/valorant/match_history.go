@@ -16,7 +16,7 @@ type MatchHistoryResponse struct {
 }
 
 func (c *Client) GetMatchHistory(shard, puuid, accessToken, entitlementToken string) (*MatchHistoryResponse, error) {
-	url := fmt.Sprintf("https://pd.%s.a.pvp.net/match-history/v1/history/%s", shard, puuid)
+	url := c.pd(shard, fmt.Sprintf("/match-history/v1/history/%s", puuid))
 
 	var result MatchHistoryResponse
 	err := c.get(url, accessToken, entitlementToken, &result)
@@ -0,0 +1,168 @@
+// Package pdtest runs a fake riot player data (PD) server that answers from
+// JSON fixtures, so resolution can be tested without network access
+package pdtest
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixtures are the recorded and sanitized responses the server answers with
+// by default. match history and mmr are keyed by puuid, match details by
+// match id
+func Fixtures() fs.FS {
+	sub, _ := fs.Sub(fixtures, "fixtures")
+	return sub
+}
+
+type failure struct {
+	status    int
+	remaining int
+}
+
+// Server serves PD under /{shard}, point a valorant.Client at URL()
+type Server struct {
+	server   *httptest.Server
+	fixtures fs.FS
+
+	mu       sync.Mutex
+	failures map[string]*failure
+	hits     map[string]int
+}
+
+// NewServer starts a fake PD server answering from fixtures
+func NewServer(fixtures fs.FS) *Server {
+	s := &Server{
+		fixtures: fixtures,
+		failures: make(map[string]*failure),
+		hits:     make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{shard}/match-history/v1/history/{puuid}", s.fixture("match-history", "puuid"))
+	mux.HandleFunc("GET /{shard}/match-details/v1/matches/{matchID}", s.fixture("match-details", "matchID"))
+	mux.HandleFunc("GET /{shard}/mmr/v1/players/{puuid}", s.fixture("mmr", "puuid"))
+	mux.HandleFunc("GET /{shard}/content-service/v3/content", s.fixture("", ""))
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// PD wants both tokens on every request
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") || r.Header.Get("X-Riot-Entitlements-JWT") == "" {
+			writeError(w, http.StatusUnauthorized, "BAD_CLAIMS", "Failure validating/decoding RSO Access Token")
+			return
+		}
+		if s.script(w, r) {
+			mux.ServeHTTP(w, r)
+		}
+	}))
+
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL is the pd url template for valorant.NewClient
+func (s *Server) URL() string {
+	return s.server.URL + "/{shard}"
+}
+
+// Fail answers the next times requests whose path, without the shard, starts
+// with prefix with status. times <= 0 fails until ClearFailures. a 429 comes
+// with a Retry-After header like riot's
+func (s *Server) Fail(prefix string, status, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[prefix] = &failure{status: status, remaining: times}
+}
+
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = make(map[string]*failure)
+}
+
+// Hits counts the requests so far whose path, with the shard, starts with
+// prefix, e.g. "/eu/match-history/"
+func (s *Server) Hits(prefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hits := 0
+	for p, n := range s.hits {
+		if strings.HasPrefix(p, prefix) {
+			hits += n
+		}
+	}
+	return hits
+}
+
+// script counts the request and applies failures, false means it was answered
+func (s *Server) script(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	s.hits[r.URL.Path]++
+
+	// strip the shard, /eu/match-history/... becomes /match-history/...
+	route := r.URL.Path
+	if i := strings.Index(strings.TrimPrefix(route, "/"), "/"); i >= 0 {
+		route = route[i+1:]
+	}
+
+	status := 0
+	for prefix, f := range s.failures {
+		if !strings.HasPrefix(route, prefix) {
+			continue
+		}
+		status = f.status
+		if f.remaining > 0 {
+			if f.remaining--; f.remaining == 0 {
+				delete(s.failures, prefix)
+			}
+		}
+		break
+	}
+	s.mu.Unlock()
+
+	if status == 0 {
+		return true
+	}
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "10")
+	}
+	writeError(w, status, "SCRIPTED", http.StatusText(status))
+	return false
+}
+
+// fixture serves dir/{param}.json, or content.json when dir is empty
+func (s *Server) fixture(dir, param string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := "content.json"
+		if dir != "" {
+			name = path.Join(dir, r.PathValue(param)+".json")
+		}
+
+		data, err := fs.ReadFile(s.fixtures, name)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", fmt.Sprintf("no fixture %s", name))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"httpStatus":%d,"errorCode":%q,"message":%q}`, status, code, message)
+}
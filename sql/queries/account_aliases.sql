@@ -0,0 +1,27 @@
+-- name: UpsertAccountAlias :exec
+INSERT INTO account_aliases (puuid, name, tag, first_seen, last_seen)
+VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+ON CONFLICT(puuid, name, tag) DO UPDATE SET
+    last_seen = CURRENT_TIMESTAMP;
+
+-- name: GetAccountAliases :many
+SELECT * FROM account_aliases
+WHERE puuid = ?
+ORDER BY last_seen DESC;
+
+-- name: GetAliasPUUIDByNameTag :one
+SELECT puuid FROM account_aliases
+WHERE name = ? AND tag = ?
+ORDER BY last_seen DESC
+LIMIT 1;
+
+-- name: ListAccountAliases :many
+SELECT * FROM account_aliases
+ORDER BY puuid, first_seen;
+
+-- name: ImportAccountAlias :exec
+INSERT INTO account_aliases (puuid, name, tag, first_seen, last_seen)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(puuid, name, tag) DO UPDATE SET
+    first_seen = MIN(account_aliases.first_seen, excluded.first_seen),
+    last_seen = MAX(account_aliases.last_seen, excluded.last_seen);
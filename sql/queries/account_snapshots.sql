@@ -0,0 +1,30 @@
+-- name: GetLatestAccountSnapshot :one
+SELECT * FROM account_snapshots
+WHERE puuid = ?
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: InsertAccountSnapshot :exec
+INSERT INTO account_snapshots (puuid, account_level, card, title, created_at)
+VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP);
+
+-- name: GetAccountSnapshots :many
+SELECT * FROM account_snapshots
+WHERE puuid = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
+
+-- name: ListAccountSnapshots :many
+SELECT * FROM account_snapshots
+ORDER BY id;
+
+-- name: ImportAccountSnapshot :exec
+-- timestamps are compared through datetime(), the driver and CURRENT_TIMESTAMP
+-- write them in different formats
+INSERT INTO account_snapshots (puuid, account_level, card, title, created_at)
+SELECT ?1, ?2, ?3, ?4, ?5
+WHERE NOT EXISTS (
+    SELECT 1 FROM account_snapshots
+    WHERE puuid = ?1 AND account_level = ?2 AND card = ?3 AND title = ?4
+        AND datetime(created_at) = datetime(?5)
+);
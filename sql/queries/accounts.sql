@@ -1,6 +1,7 @@
 -- name: GetAccountByNameTag :one
 SELECT * FROM accounts
 WHERE name = ? AND tag = ?
+ORDER BY updated_at DESC
 LIMIT 1;
 
 -- name: GetAccountByPUUID :one
@@ -22,4 +23,23 @@ ON CONFLICT(puuid) DO UPDATE SET
 
 -- name: CleanOldAccounts :exec
 DELETE FROM accounts
-WHERE updated_at < datetime('now', '-7 days');
+WHERE updated_at < ?;
+
+-- name: ListAccounts :many
+SELECT * FROM accounts
+ORDER BY puuid;
+
+-- name: ImportAccount :exec
+-- timestamps are compared through datetime(), the driver and CURRENT_TIMESTAMP
+-- write them in different formats
+INSERT INTO accounts (puuid, region, account_level, name, tag, card, title, updated_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(puuid) DO UPDATE SET
+    region = excluded.region,
+    account_level = excluded.account_level,
+    name = excluded.name,
+    tag = excluded.tag,
+    card = excluded.card,
+    title = excluded.title,
+    updated_at = excluded.updated_at
+WHERE datetime(excluded.updated_at) > datetime(accounts.updated_at);
@@ -1,8 +1,9 @@
 -- name: RegisterClient :exec
-INSERT INTO clients (client_id, version, last_heartbeat, lcu_available, connected_at)
-VALUES (?, ?, CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP)
+INSERT INTO clients (client_id, version, remote_address, last_heartbeat, lcu_available, connected_at)
+VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP)
 ON CONFLICT(client_id) DO UPDATE SET
     version = excluded.version,
+    remote_address = excluded.remote_address,
     last_heartbeat = CURRENT_TIMESTAMP,
     lcu_available = excluded.lcu_available,
     connected_at = CURRENT_TIMESTAMP;
@@ -10,12 +11,24 @@ ON CONFLICT(client_id) DO UPDATE SET
 -- name: UpdateClientHeartbeat :exec
 UPDATE clients
 SET last_heartbeat = CURRENT_TIMESTAMP,
-    lcu_available = ?
+    lcu_available = ?,
+    game_version = ?,
+    status = ?,
+    status_detail = ?,
+    friend_request_backlog = ?
 WHERE client_id = ?;
 
+-- name: RecordClientRequest :exec
+UPDATE clients
+SET requests_served = requests_served + 1,
+    failures = failures + sqlc.arg(failures),
+    total_latency_ms = total_latency_ms + sqlc.arg(latency_ms),
+    last_error = COALESCE(sqlc.narg(last_error), last_error)
+WHERE client_id = sqlc.arg(client_id);
+
 -- name: GetAvailableClients :many
 SELECT * FROM clients
-WHERE lcu_available = TRUE
+WHERE (status = 'ready' OR (status = '' AND lcu_available = TRUE))
   AND last_heartbeat > datetime('now', '-30 seconds')
 ORDER BY last_heartbeat DESC;
 
@@ -29,4 +42,4 @@ WHERE client_id = ?;
 
 -- name: CleanStaleClients :exec
 DELETE FROM clients
-WHERE last_heartbeat < datetime('now', '-2 minutes');
+WHERE last_heartbeat < ?;
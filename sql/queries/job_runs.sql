@@ -0,0 +1,8 @@
+-- name: GetJobRuns :many
+SELECT * FROM job_runs;
+
+-- name: RecordJobRun :exec
+INSERT INTO job_runs (name, last_run)
+VALUES (?, ?)
+ON CONFLICT(name) DO UPDATE SET
+    last_run = excluded.last_run;
@@ -0,0 +1,21 @@
+-- name: GetNegativeLookup :one
+SELECT * FROM negative_lookups
+WHERE name = ? AND tag = ?
+LIMIT 1;
+
+-- name: UpsertNegativeLookup :exec
+INSERT INTO negative_lookups (name, tag, error_code, error_message, expires_at, created_at)
+VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT(name, tag) DO UPDATE SET
+    error_code = excluded.error_code,
+    error_message = excluded.error_message,
+    expires_at = excluded.expires_at,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteNegativeLookup :exec
+DELETE FROM negative_lookups
+WHERE name = ? AND tag = ?;
+
+-- name: CleanExpiredNegativeLookups :exec
+DELETE FROM negative_lookups
+WHERE expires_at < ?;
@@ -0,0 +1,30 @@
+-- name: GetLatestAccountSnapshot :one
+SELECT * FROM account_snapshots
+WHERE puuid = $1
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: InsertAccountSnapshot :exec
+INSERT INTO account_snapshots (puuid, account_level, card, title, created_at)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP);
+
+-- name: GetAccountSnapshots :many
+SELECT * FROM account_snapshots
+WHERE puuid = $1
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit')::bigint;
+
+-- name: ListAccountSnapshots :many
+SELECT * FROM account_snapshots
+ORDER BY id;
+
+-- name: ImportAccountSnapshot :exec
+-- exports only keep whole seconds. the casts type the parameters, a select
+-- list doesn't
+INSERT INTO account_snapshots (puuid, account_level, card, title, created_at)
+SELECT sqlc.arg(puuid)::text, sqlc.arg(account_level)::bigint, sqlc.arg(card)::text, sqlc.arg(title)::text, sqlc.arg(created_at)::timestamptz
+WHERE NOT EXISTS (
+    SELECT 1 FROM account_snapshots
+    WHERE puuid = sqlc.arg(puuid) AND account_level = sqlc.arg(account_level) AND card = sqlc.arg(card) AND title = sqlc.arg(title)
+        AND date_trunc('second', created_at) = date_trunc('second', sqlc.arg(created_at))
+);
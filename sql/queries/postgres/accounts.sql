@@ -0,0 +1,43 @@
+-- name: GetAccountByNameTag :one
+SELECT * FROM accounts
+WHERE lower(name) = lower(sqlc.arg(name)) AND lower(tag) = lower(sqlc.arg(tag))
+ORDER BY updated_at DESC
+LIMIT 1;
+
+-- name: GetAccountByPUUID :one
+SELECT * FROM accounts
+WHERE puuid = $1
+LIMIT 1;
+
+-- name: UpsertAccount :exec
+INSERT INTO accounts (puuid, region, account_level, name, tag, card, title, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+ON CONFLICT(puuid) DO UPDATE SET
+    region = excluded.region,
+    account_level = excluded.account_level,
+    name = excluded.name,
+    tag = excluded.tag,
+    card = excluded.card,
+    title = excluded.title,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: CleanOldAccounts :exec
+DELETE FROM accounts
+WHERE updated_at < $1;
+
+-- name: ListAccounts :many
+SELECT * FROM accounts
+ORDER BY puuid;
+
+-- name: ImportAccount :exec
+INSERT INTO accounts (puuid, region, account_level, name, tag, card, title, updated_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT(puuid) DO UPDATE SET
+    region = excluded.region,
+    account_level = excluded.account_level,
+    name = excluded.name,
+    tag = excluded.tag,
+    card = excluded.card,
+    title = excluded.title,
+    updated_at = excluded.updated_at
+WHERE excluded.updated_at > accounts.updated_at;
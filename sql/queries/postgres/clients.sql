@@ -0,0 +1,45 @@
+-- name: RegisterClient :exec
+INSERT INTO clients (client_id, version, remote_address, last_heartbeat, lcu_available, connected_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP, $4, CURRENT_TIMESTAMP)
+ON CONFLICT(client_id) DO UPDATE SET
+    version = excluded.version,
+    remote_address = excluded.remote_address,
+    last_heartbeat = CURRENT_TIMESTAMP,
+    lcu_available = excluded.lcu_available,
+    connected_at = CURRENT_TIMESTAMP;
+
+-- name: UpdateClientHeartbeat :exec
+UPDATE clients
+SET last_heartbeat = CURRENT_TIMESTAMP,
+    lcu_available = $1,
+    game_version = $2,
+    status = $3,
+    status_detail = $4,
+    friend_request_backlog = $5
+WHERE client_id = $6;
+
+-- name: RecordClientRequest :exec
+UPDATE clients
+SET requests_served = requests_served + 1,
+    failures = failures + sqlc.arg(failures),
+    total_latency_ms = total_latency_ms + sqlc.arg(latency_ms),
+    last_error = COALESCE(sqlc.narg(last_error), last_error)
+WHERE client_id = sqlc.arg(client_id);
+
+-- name: GetAvailableClients :many
+SELECT * FROM clients
+WHERE (status = 'ready' OR (status = '' AND lcu_available = TRUE))
+  AND last_heartbeat > CURRENT_TIMESTAMP - INTERVAL '30 seconds'
+ORDER BY last_heartbeat DESC;
+
+-- name: GetAllClients :many
+SELECT * FROM clients
+ORDER BY last_heartbeat DESC;
+
+-- name: RemoveClient :exec
+DELETE FROM clients
+WHERE client_id = $1;
+
+-- name: CleanStaleClients :exec
+DELETE FROM clients
+WHERE last_heartbeat < $1;
@@ -0,0 +1,21 @@
+-- name: GetNegativeLookup :one
+SELECT * FROM negative_lookups
+WHERE lower(name) = lower(sqlc.arg(name)) AND lower(tag) = lower(sqlc.arg(tag))
+LIMIT 1;
+
+-- name: UpsertNegativeLookup :exec
+INSERT INTO negative_lookups (name, tag, error_code, error_message, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+ON CONFLICT (lower(name), lower(tag)) DO UPDATE SET
+    error_code = excluded.error_code,
+    error_message = excluded.error_message,
+    expires_at = excluded.expires_at,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteNegativeLookup :exec
+DELETE FROM negative_lookups
+WHERE lower(name) = lower(sqlc.arg(name)) AND lower(tag) = lower(sqlc.arg(tag));
+
+-- name: CleanExpiredNegativeLookups :exec
+DELETE FROM negative_lookups
+WHERE expires_at < $1;
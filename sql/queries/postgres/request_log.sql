@@ -0,0 +1,44 @@
+-- name: LogRequest :exec
+INSERT INTO request_log (request_id, method, client_id, game_name, game_tag, puuid, source, success, error_code, error_message, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: GetRequestStats :one
+SELECT
+    COUNT(*) as total_requests,
+    COALESCE(SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END), 0)::float8 as successful_requests,
+    COALESCE(AVG(duration_ms), 0)::float8 as avg_duration_ms
+FROM request_log
+-- admin calls are audited too but aren't api traffic
+WHERE created_at > $1 AND method NOT IN ('GetStats', 'ListNodes');
+
+-- name: GetRequestDurationAt :one
+SELECT duration_ms FROM request_log
+WHERE created_at > $1 AND duration_ms IS NOT NULL AND method NOT IN ('GetStats', 'ListNodes')
+ORDER BY duration_ms
+LIMIT 1 OFFSET sqlc.arg('offset')::bigint;
+
+-- name: GetRequestStatsByClient :many
+SELECT
+    client_id,
+    COUNT(*) as total_requests,
+    COALESCE(SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END), 0)::float8 as successful_requests,
+    COALESCE(AVG(duration_ms), 0)::float8 as avg_duration_ms
+FROM request_log
+WHERE created_at > $1 AND client_id IS NOT NULL
+GROUP BY client_id
+ORDER BY total_requests DESC;
+
+-- name: GetRequestStatsBySource :many
+SELECT
+    source,
+    COUNT(*) as total_requests,
+    COALESCE(SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END), 0)::float8 as successful_requests,
+    COALESCE(AVG(duration_ms), 0)::float8 as avg_duration_ms
+FROM request_log
+WHERE created_at > $1 AND source IS NOT NULL
+GROUP BY source
+ORDER BY total_requests DESC;
+
+-- name: CleanOldLogs :exec
+DELETE FROM request_log
+WHERE created_at < $1;
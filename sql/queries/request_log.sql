@@ -1,6 +1,6 @@
 -- name: LogRequest :exec
-INSERT INTO request_log (request_id, client_id, game_name, game_tag, puuid, success, error_message, duration_ms)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO request_log (request_id, method, client_id, game_name, game_tag, puuid, source, success, error_code, error_message, duration_ms)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetRequestStats :one
 SELECT
@@ -8,8 +8,37 @@ SELECT
     SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END) as successful_requests,
     AVG(duration_ms) as avg_duration_ms
 FROM request_log
-WHERE created_at > datetime('now', '-1 hour');
+-- admin calls are audited too but aren't api traffic
+WHERE created_at > ? AND method NOT IN ('GetStats', 'ListNodes');
+
+-- name: GetRequestDurationAt :one
+SELECT duration_ms FROM request_log
+WHERE created_at > ? AND duration_ms IS NOT NULL AND method NOT IN ('GetStats', 'ListNodes')
+ORDER BY duration_ms
+LIMIT 1 OFFSET ?;
+
+-- name: GetRequestStatsByClient :many
+SELECT
+    client_id,
+    COUNT(*) as total_requests,
+    SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END) as successful_requests,
+    AVG(duration_ms) as avg_duration_ms
+FROM request_log
+WHERE created_at > ? AND client_id IS NOT NULL
+GROUP BY client_id
+ORDER BY total_requests DESC;
+
+-- name: GetRequestStatsBySource :many
+SELECT
+    source,
+    COUNT(*) as total_requests,
+    SUM(CASE WHEN success = TRUE THEN 1 ELSE 0 END) as successful_requests,
+    AVG(duration_ms) as avg_duration_ms
+FROM request_log
+WHERE created_at > ? AND source IS NOT NULL
+GROUP BY source
+ORDER BY total_requests DESC;
 
 -- name: CleanOldLogs :exec
 DELETE FROM request_log
-WHERE created_at < datetime('now', '-30 days');
+WHERE created_at < ?;
@@ -0,0 +1,15 @@
+-- account_aliases table: every riot id an account has been seen under
+CREATE TABLE IF NOT EXISTS account_aliases (
+    puuid TEXT NOT NULL,
+    name TEXT NOT NULL COLLATE NOCASE,
+    tag TEXT NOT NULL COLLATE NOCASE,
+    first_seen TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (puuid, name, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_aliases_name_tag ON account_aliases(name, tag);
+
+-- seed with the names already known
+INSERT OR IGNORE INTO account_aliases (puuid, name, tag, first_seen, last_seen)
+SELECT puuid, name, tag, created_at, updated_at FROM accounts;
@@ -0,0 +1,7 @@
+-- clients: where a node connects from, what it runs and how it has been doing
+ALTER TABLE clients ADD COLUMN remote_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE clients ADD COLUMN game_version TEXT NOT NULL DEFAULT '';
+ALTER TABLE clients ADD COLUMN requests_served INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE clients ADD COLUMN failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE clients ADD COLUMN total_latency_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE clients ADD COLUMN last_error TEXT;
@@ -0,0 +1,42 @@
+-- accounts and negative_lookups: riot ids are case insensitive, sqlite can't
+-- change a column's collation so both tables are rebuilt
+CREATE TABLE accounts_nocase (
+    puuid TEXT PRIMARY KEY,
+    region TEXT NOT NULL,
+    account_level INTEGER NOT NULL,
+    name TEXT NOT NULL COLLATE NOCASE,
+    tag TEXT NOT NULL COLLATE NOCASE,
+    card TEXT NOT NULL,
+    title TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO accounts_nocase (puuid, region, account_level, name, tag, card, title, updated_at, created_at)
+SELECT puuid, region, account_level, name, tag, card, title, updated_at, created_at FROM accounts;
+
+DROP TABLE accounts;
+ALTER TABLE accounts_nocase RENAME TO accounts;
+
+CREATE INDEX IF NOT EXISTS idx_accounts_name_tag ON accounts(name, tag);
+CREATE INDEX IF NOT EXISTS idx_accounts_updated_at ON accounts(updated_at);
+
+CREATE TABLE negative_lookups_nocase (
+    name TEXT NOT NULL COLLATE NOCASE,
+    tag TEXT NOT NULL COLLATE NOCASE,
+    error_code TEXT NOT NULL,
+    error_message TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (name, tag)
+);
+
+-- riot ids now colliding keep the lookup that expires last
+INSERT OR REPLACE INTO negative_lookups_nocase (name, tag, error_code, error_message, expires_at, created_at)
+SELECT name, tag, error_code, error_message, expires_at, created_at FROM negative_lookups
+ORDER BY expires_at;
+
+DROP TABLE negative_lookups;
+ALTER TABLE negative_lookups_nocase RENAME TO negative_lookups;
+
+CREATE INDEX IF NOT EXISTS idx_negative_lookups_expires_at ON negative_lookups(expires_at);
@@ -0,0 +1,12 @@
+-- negative_lookups table: riot ids that recently failed to resolve
+CREATE TABLE IF NOT EXISTS negative_lookups (
+    name TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    error_code TEXT NOT NULL,
+    error_message TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (name, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_negative_lookups_expires_at ON negative_lookups(expires_at);
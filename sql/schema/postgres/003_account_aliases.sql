@@ -0,0 +1,17 @@
+-- account_aliases table: every riot id an account has been seen under,
+-- riot ids are case insensitive so uniqueness is on the lowercased name
+CREATE TABLE IF NOT EXISTS account_aliases (
+    puuid TEXT NOT NULL,
+    name TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    first_seen TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_aliases_puuid_name_tag ON account_aliases(puuid, lower(name), lower(tag));
+CREATE INDEX IF NOT EXISTS idx_account_aliases_name_tag ON account_aliases(lower(name), lower(tag));
+
+-- seed with the names already known
+INSERT INTO account_aliases (puuid, name, tag, first_seen, last_seen)
+SELECT puuid, name, tag, created_at, updated_at FROM accounts
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,16 @@
+-- account_snapshots table: level, card and title of an account over time,
+-- a row is added whenever one of them changes
+CREATE TABLE IF NOT EXISTS account_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    puuid TEXT NOT NULL,
+    account_level BIGINT NOT NULL,
+    card TEXT NOT NULL,
+    title TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_snapshots_puuid ON account_snapshots(puuid, id);
+
+-- seed with the state already known
+INSERT INTO account_snapshots (puuid, account_level, card, title, created_at)
+SELECT puuid, account_level, card, title, updated_at FROM accounts;
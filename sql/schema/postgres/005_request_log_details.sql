@@ -0,0 +1,6 @@
+-- request_log: which rpc was called, where the data came from and why it failed
+ALTER TABLE request_log ADD COLUMN IF NOT EXISTS method TEXT NOT NULL DEFAULT '';
+ALTER TABLE request_log ADD COLUMN IF NOT EXISTS source TEXT;
+ALTER TABLE request_log ADD COLUMN IF NOT EXISTS error_code TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_request_log_client_id ON request_log(client_id);
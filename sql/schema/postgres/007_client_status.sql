@@ -0,0 +1,3 @@
+-- clients: the health a node last reported, ready nodes are the only ones routed to
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT '';
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS status_detail TEXT NOT NULL DEFAULT '';
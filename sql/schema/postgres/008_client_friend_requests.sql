@@ -0,0 +1,2 @@
+-- clients: outgoing friend requests pending on the node's account, riot caps them
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS friend_request_backlog BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- job_runs table: when each maintenance job last started, so a restart doesn't
+-- reset the schedule
+CREATE TABLE IF NOT EXISTS job_runs (
+    name TEXT PRIMARY KEY,
+    last_run TIMESTAMPTZ NOT NULL
+);
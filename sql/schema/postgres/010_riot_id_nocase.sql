@@ -0,0 +1,13 @@
+-- accounts and negative_lookups: riot ids are case insensitive, lookups match
+-- on the lowercased name
+DROP INDEX IF EXISTS idx_accounts_name_tag;
+CREATE INDEX IF NOT EXISTS idx_accounts_name_tag ON accounts(lower(name), lower(tag));
+
+-- riot ids now colliding keep the lookup that expires last
+DELETE FROM negative_lookups a
+USING negative_lookups b
+WHERE lower(a.name) = lower(b.name) AND lower(a.tag) = lower(b.tag)
+    AND (a.expires_at, a.ctid) < (b.expires_at, b.ctid);
+
+ALTER TABLE negative_lookups DROP CONSTRAINT IF EXISTS negative_lookups_pkey;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_negative_lookups_name_tag ON negative_lookups(lower(name), lower(tag));
@@ -0,0 +1,15 @@
+// Package schema embeds the database migrations so the master does not
+// depend on the directory it is started from
+package schema
+
+import "embed"
+
+// Migrations holds the SQLite migrations
+//
+//go:embed *.sql
+var Migrations embed.FS
+
+// PostgresMigrations holds the PostgreSQL migrations under postgres/
+//
+//go:embed postgres/*.sql
+var PostgresMigrations embed.FS